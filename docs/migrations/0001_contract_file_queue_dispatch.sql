@@ -0,0 +1,11 @@
+-- Columns the contract file queue needs for the dispatcher. Run once against
+-- existing deployments that do not let the service migrate the schema
+-- (SCHEMA_BOOTSTRAP=verify or off), before deploying the dispatcher:
+--
+--   bq query --use_legacy_sql=false < docs/migrations/0001_contract_file_queue_dispatch.sql
+ALTER TABLE audit_layer.contract_file_queue
+  ADD COLUMN IF NOT EXISTS jobName STRING,
+  ADD COLUMN IF NOT EXISTS priority INT64,
+  ADD COLUMN IF NOT EXISTS jobRequest STRING,
+  ADD COLUMN IF NOT EXISTS operation STRING,
+  ADD COLUMN IF NOT EXISTS dispatchedAt TIMESTAMP;
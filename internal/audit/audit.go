@@ -0,0 +1,81 @@
+// Package audit writes audit events to one or more sinks. The BigQuery audit
+// table is the production sink; a JSON Lines file, the zap logger and an
+// in-memory sink serve local runs and tests. Sinks can be combined to write
+// everywhere at once or to fall back to a local sink when BigQuery fails.
+package audit
+
+import (
+	"context"
+	"errors"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+)
+
+// AuditSink receives audit events. Implementations must be safe for
+// concurrent use and report events they could not write as errors.
+// *bigquery.Client and *bigquery.AuditWriter implement it.
+type AuditSink interface {
+	LogAuditData(ctx context.Context, event model.AuditEvent) error
+	Close(ctx context.Context) error
+}
+
+// fanOut writes every event to all of its sinks.
+type fanOut []AuditSink
+
+// FanOut returns a sink writing every event to each of the sinks. An event
+// is written to all of them even when one fails, and the failures are joined.
+func FanOut(sinks ...AuditSink) AuditSink {
+	return fanOut(sinks)
+}
+
+// LogAuditData writes the event to every sink.
+func (f fanOut) LogAuditData(ctx context.Context, event model.AuditEvent) error {
+	var errs []error
+	for _, sink := range f {
+		if err := sink.LogAuditData(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes every sink.
+func (f fanOut) Close(ctx context.Context) error {
+	var errs []error
+	for _, sink := range f {
+		if err := sink.Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Fallback writes to its primary sink and hands the event to the secondary
+// sink when the primary fails.
+type Fallback struct {
+	primary   AuditSink
+	secondary AuditSink
+}
+
+// NewFallback returns a sink falling back from primary to secondary.
+func NewFallback(primary AuditSink, secondary AuditSink) *Fallback {
+	return &Fallback{primary: primary, secondary: secondary}
+}
+
+// LogAuditData writes the event to the primary sink, or to the secondary
+// sink when that fails. It only reports an error when both fail.
+func (f *Fallback) LogAuditData(ctx context.Context, event model.AuditEvent) error {
+	err := f.primary.LogAuditData(ctx, event)
+	if err == nil {
+		return nil
+	}
+	if fallbackErr := f.secondary.LogAuditData(ctx, event); fallbackErr != nil {
+		return errors.Join(err, fallbackErr)
+	}
+	return nil
+}
+
+// Close closes both sinks.
+func (f *Fallback) Close(ctx context.Context) error {
+	return errors.Join(f.primary.Close(ctx), f.secondary.Close(ctx))
+}
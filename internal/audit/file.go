@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+)
+
+// File appends audit events to a JSON Lines file, one event per line.
+type File struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFile opens the file for appending, creating it when it does not exist.
+func NewFile(path string) (*File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open audit file %s: %v", path, err)
+	}
+	return &File{file: file}, nil
+}
+
+// LogAuditData appends the event as a JSON line.
+func (f *File) LogAuditData(ctx context.Context, event model.AuditEvent) error {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("unable to encode audit event: %v", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("unable to write audit event: %v", err)
+	}
+	return nil
+}
+
+// Close closes the file.
+func (f *File) Close(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
@@ -0,0 +1,45 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+	"go.uber.org/zap"
+)
+
+// Log writes audit events as structured log entries, which end up on stdout
+// and in Cloud Logging.
+type Log struct {
+	logger *zap.Logger
+}
+
+// NewLog returns a sink logging to the logger.
+func NewLog(logger *zap.Logger) *Log {
+	return &Log{logger: logger}
+}
+
+// LogAuditData logs the event with its fields.
+func (l *Log) LogAuditData(ctx context.Context, event model.AuditEvent) error {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	l.logger.Info("audit event",
+		zap.String("applicationName", constants.APPLICATION_NAME),
+		zap.String("traceId", event.TraceID),
+		zap.String("contractId", event.ContractId),
+		zap.String("event", event.Event),
+		zap.String("status", event.Status),
+		zap.Time("timestamp", event.Timestamp),
+		zap.String("functionName", event.FunctionName),
+		zap.String("fileUrl", event.FileUrl),
+		zap.String("message", event.Message))
+	return nil
+}
+
+// Close flushes the logger.
+func (l *Log) Close(ctx context.Context) error {
+	l.logger.Sync()
+	return nil
+}
@@ -0,0 +1,51 @@
+package audit
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+)
+
+// Memory keeps audit events in memory, for tests and local runs.
+type Memory struct {
+	// Err, when set before use, is returned by every LogAuditData call
+	// instead of recording the event.
+	Err error
+
+	mu     sync.Mutex
+	events []model.AuditEvent
+}
+
+// NewMemory returns an empty in-memory sink.
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+// LogAuditData records the event.
+func (m *Memory) LogAuditData(ctx context.Context, event model.AuditEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	m.events = append(m.events, event)
+	return nil
+}
+
+// Events returns the recorded events in the order they were logged.
+func (m *Memory) Events() []model.AuditEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.events)
+}
+
+// Close does nothing; the events stay readable.
+func (m *Memory) Close(ctx context.Context) error {
+	return nil
+}
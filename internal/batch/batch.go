@@ -0,0 +1,163 @@
+// Package batch provides a wrapper around the Google Cloud Batch client.
+// It submits container jobs for files too large for Cloud Run, sized with the
+// machine type, disk and maximum runtime derived from the file metadata.
+package batch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	batch "cloud.google.com/go/batch/apiv1"
+	"cloud.google.com/go/batch/apiv1/batchpb"
+)
+
+// maxJobIdPrefix leaves room for the random suffix within the 63 character
+// limit on Cloud Batch job IDs.
+const maxJobIdPrefix = 54
+
+// Batch wraps the Cloud Batch client with logging and traceability context.
+type Batch struct {
+	logger  *zap.Logger   // Logger for structured logging
+	client  *batch.Client // Google Cloud Batch client
+	traceId string        // Trace ID for request tracking
+}
+
+// NewBatch initializes and returns a new Batch instance.
+func NewBatch(ctx context.Context, logger *zap.Logger, traceId string) (*Batch, error) {
+	client, err := batch.NewClient(ctx)
+	if err != nil {
+		logger.Error("unable to create cloud batch client",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", traceId),
+			zap.Error(err))
+		return nil, err
+	}
+	return &Batch{
+		logger:  logger,
+		client:  client,
+		traceId: traceId,
+	}, nil
+}
+
+// SubmitJob creates a Cloud Batch job running the container image with the
+// given arguments on the requested machine type, boot disk size and maximum
+// runtime. The optional profile sets the environment, task count, parallelism
+// and per-task CPU and memory; a single task is run by default. It returns the
+// full name of the created job.
+func (b *Batch) SubmitJob(ctx context.Context, projectId string, region string, jobName string, args []string, resources model.BatchResources, profile *model.ResourceProfile) (string, error) {
+	parent := fmt.Sprintf(constants.BATCH_JOB_PARENT, projectId, region)
+	jobId := newJobId(jobName)
+	b.logger.Info("attempting to submit cloud batch job",
+		zap.String("applicationName", constants.APPLICATION_NAME),
+		zap.String("traceId", b.traceId),
+		zap.String("region", region),
+		zap.String("jobId", jobId),
+		zap.String("machineType", resources.MachineType),
+		zap.Int64("diskSizeGB", resources.DiskSizeGB),
+		zap.Int64("maxRunTimeMinutes", resources.MaxRunTimeMinutes))
+
+	taskSpec := &batchpb.TaskSpec{
+		Runnables: []*batchpb.Runnable{
+			{
+				Executable: &batchpb.Runnable_Container_{
+					Container: &batchpb.Runnable_Container{
+						ImageUri: resources.ImageUri,
+						Commands: args,
+					},
+				},
+			},
+		},
+		MaxRunDuration: durationpb.New(time.Duration(resources.MaxRunTimeMinutes) * time.Minute),
+	}
+	taskGroup := &batchpb.TaskGroup{TaskCount: 1, TaskSpec: taskSpec}
+	if profile != nil {
+		if len(profile.Env) > 0 {
+			taskSpec.Environment = &batchpb.Environment{Variables: profile.Env}
+		}
+		if profile.CpuMilli > 0 || profile.MemoryMib > 0 {
+			taskSpec.ComputeResource = &batchpb.ComputeResource{CpuMilli: profile.CpuMilli, MemoryMib: profile.MemoryMib}
+		}
+		if profile.TaskCount > 0 {
+			taskGroup.TaskCount = int64(profile.TaskCount)
+		}
+		taskGroup.Parallelism = int64(profile.Parallelism)
+	}
+
+	req := &batchpb.CreateJobRequest{
+		Parent: parent,
+		JobId:  jobId,
+		Job: &batchpb.Job{
+			Labels:     map[string]string{"trace-id": b.traceId},
+			TaskGroups: []*batchpb.TaskGroup{taskGroup},
+			AllocationPolicy: &batchpb.AllocationPolicy{
+				Instances: []*batchpb.AllocationPolicy_InstancePolicyOrTemplate{
+					{
+						PolicyTemplate: &batchpb.AllocationPolicy_InstancePolicyOrTemplate_Policy{
+							Policy: &batchpb.AllocationPolicy_InstancePolicy{
+								MachineType: resources.MachineType,
+								BootDisk:    &batchpb.AllocationPolicy_Disk{SizeGb: resources.DiskSizeGB},
+							},
+						},
+					},
+				},
+			},
+			LogsPolicy: &batchpb.LogsPolicy{Destination: batchpb.LogsPolicy_CLOUD_LOGGING},
+		},
+	}
+
+	job, err := b.client.CreateJob(ctx, req)
+	if err != nil {
+		b.logger.Error("failed to submit cloud batch job",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", b.traceId),
+			zap.String("jobId", jobId),
+			zap.Error(err))
+		return "", err
+	}
+
+	b.logger.Info("submitted cloud batch job",
+		zap.String("applicationName", constants.APPLICATION_NAME),
+		zap.String("traceId", b.traceId),
+		zap.String("jobName", job.GetName()))
+
+	return job.GetName(), nil
+}
+
+// Close closes the Cloud Batch client to free resources.
+func (b *Batch) Close() error {
+	if err := b.client.Close(); err != nil {
+		b.logger.Error("unable to close cloud batch client",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", b.traceId),
+			zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// newJobId builds a unique job ID from the job name. Cloud Batch IDs must
+// start with a letter and contain only lower-case letters, digits and hyphens.
+func newJobId(jobName string) string {
+	prefix := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(jobName))
+	if prefix == "" || prefix[0] < 'a' || prefix[0] > 'z' {
+		prefix = "job-" + prefix
+	}
+	if len(prefix) > maxJobIdPrefix {
+		prefix = prefix[:maxJobIdPrefix]
+	}
+	prefix = strings.TrimRight(prefix, "-")
+	return prefix + "-" + uuid.NewString()[:8]
+}
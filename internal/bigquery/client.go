@@ -5,6 +5,8 @@ package bigquery
 import (
 	"context"
 	"fmt"
+	"math/rand/v2"
+	"strings"
 	"time"
 
 	bq "cloud.google.com/go/bigquery"
@@ -12,6 +14,7 @@ import (
 	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
 
 	"go.uber.org/zap"
+	"google.golang.org/api/iterator"
 )
 
 // Client wraps the BigQuery client and includes context for logging and traceability.
@@ -45,6 +48,7 @@ func NewClient(ctx context.Context, logger *zap.Logger, projectId string, traceI
 
 // LogAuditData logs audit trail events into the BigQuery audit table.
 // This includes metadata such as trace ID, event type, and timestamp.
+// The event is inserted synchronously; AuditWriter batches events instead.
 func (c *Client) LogAuditData(ctx context.Context, event model.AuditEvent) error {
 	inserter := c.client.Dataset(constants.DATASET_ID).Table(constants.TABLE_ID).Inserter()
 
@@ -58,29 +62,228 @@ func (c *Client) LogAuditData(ctx context.Context, event model.AuditEvent) error
 			zap.String("applicationName", constants.APPLICATION_NAME),
 			zap.String("traceId", c.traceId),
 			zap.Error(err))
+		return fmt.Errorf("unable to persist audit event into bigquery: %v", err)
 	}
 	return nil
 }
 
 // ContractFileQueue inserts contract-related events into the contract queue table.
-// These events may trigger downstream processing based on file events.
+// These events may trigger downstream processing based on file events. The row
+// is written with a DML INSERT rather than the streaming inserter because rows
+// still in the streaming buffer cannot be updated, and the queue status is
+// updated as the job progresses.
 func (c *Client) ContractFileQueue(ctx context.Context, event model.ContractFileEvent) error {
-	inserter := c.client.Dataset(constants.DATASET_ID).Table(constants.CONTRACT_QUEUE_TABLE).Inserter()
-
 	if event.Timestamp.IsZero() {
 		event.Timestamp = time.Now()
 	}
 
-	err := inserter.Put(ctx, []*model.ContractFileEvent{&event})
+	query := fmt.Sprintf("INSERT INTO %s (traceId, contractId, status, timestamp, functionName, arguments, environment, jobName, priority, jobRequest) "+
+		"VALUES (@traceId, @contractId, @status, @timestamp, @functionName, @arguments, @environment, @jobName, @priority, @jobRequest)", c.queueTable())
+	_, err := c.runDML(ctx, query, []bq.QueryParameter{
+		{Name: "traceId", Value: event.TraceID},
+		{Name: "contractId", Value: event.ContractID},
+		{Name: "status", Value: event.Status},
+		{Name: "timestamp", Value: event.Timestamp},
+		{Name: "functionName", Value: event.FunctionName},
+		{Name: "arguments", Value: event.Arguments},
+		{Name: "environment", Value: event.Environment},
+		{Name: "jobName", Value: event.JobName},
+		{Name: "priority", Value: event.Priority},
+		{Name: "jobRequest", Value: event.JobRequest},
+	})
 	if err != nil {
-		c.logger.Info("unable to persist data into bigquery",
+		c.logger.Error("unable to insert contract file queue entry",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", c.traceId),
+			zap.String("contractId", event.ContractID),
+			zap.Error(err))
+		return fmt.Errorf("unable to insert contract file queue entry: %v", err)
+	}
+	return nil
+}
+
+// UpdateContractFileStatus moves the contract file queue entry to the given status.
+func (c *Client) UpdateContractFileStatus(ctx context.Context, traceId string, contractId string, status string) error {
+	query := fmt.Sprintf("UPDATE %s SET status = @status WHERE traceId = @traceId AND contractId = @contractId", c.queueTable())
+	_, err := c.runDML(ctx, query, []bq.QueryParameter{
+		{Name: "status", Value: status},
+		{Name: "traceId", Value: traceId},
+		{Name: "contractId", Value: contractId},
+	})
+	if err != nil {
+		c.logger.Error("unable to update contract file queue status",
 			zap.String("applicationName", constants.APPLICATION_NAME),
 			zap.String("traceId", c.traceId),
+			zap.String("contractId", contractId),
+			zap.String("status", status),
 			zap.Error(err))
+		return fmt.Errorf("unable to update contract file queue status: %v", err)
 	}
 	return nil
 }
 
+// ClaimContractFile atomically moves a QUEUED entry to DISPATCHED while its
+// job has fewer than maxRunning entries dispatched within window. The
+// conditional update only matches while the entry is still QUEUED and the
+// running count is checked in the same statement. BigQuery does not queue
+// concurrent updates of the same partition: all but one fail with a
+// serialization error, and runDML retries those, so when several claims race
+// exactly one succeeds per free slot. It reports false when the entry was
+// taken or its job is at its limit.
+func (c *Client) ClaimContractFile(ctx context.Context, traceId string, contractId string, jobName string, maxRunning int, window time.Duration) (bool, error) {
+	query := fmt.Sprintf("UPDATE %[1]s SET status = @dispatched, dispatchedAt = CURRENT_TIMESTAMP() "+
+		"WHERE traceId = @traceId AND contractId = @contractId AND status = @queued "+
+		"AND (SELECT COUNT(*) FROM %[1]s WHERE status = @dispatched AND IFNULL(jobName, '') = @jobName "+
+		"AND dispatchedAt >= TIMESTAMP_SUB(CURRENT_TIMESTAMP(), INTERVAL @windowSeconds SECOND)) < @maxRunning", c.queueTable())
+	affected, err := c.runDML(ctx, query, []bq.QueryParameter{
+		{Name: "dispatched", Value: constants.QUEUE_STATUS_DISPATCHED},
+		{Name: "queued", Value: constants.QUEUE_STATUS_QUEUED},
+		{Name: "traceId", Value: traceId},
+		{Name: "contractId", Value: contractId},
+		{Name: "jobName", Value: jobName},
+		{Name: "maxRunning", Value: maxRunning},
+		{Name: "windowSeconds", Value: int64(window.Seconds())},
+	})
+	if err != nil {
+		c.logger.Warn("unable to claim contract file queue entry",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", c.traceId),
+			zap.String("contractId", contractId),
+			zap.Error(err))
+		return false, fmt.Errorf("unable to claim contract file queue entry: %v", err)
+	}
+	return affected == 1, nil
+}
+
+// SetContractFileOperation records the operation of the job launched for the
+// contract file queue entry.
+func (c *Client) SetContractFileOperation(ctx context.Context, traceId string, contractId string, operation string) error {
+	query := fmt.Sprintf("UPDATE %s SET operation = @operation WHERE traceId = @traceId AND contractId = @contractId", c.queueTable())
+	_, err := c.runDML(ctx, query, []bq.QueryParameter{
+		{Name: "operation", Value: operation},
+		{Name: "traceId", Value: traceId},
+		{Name: "contractId", Value: contractId},
+	})
+	if err != nil {
+		c.logger.Error("unable to record contract file operation",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", c.traceId),
+			zap.String("contractId", contractId),
+			zap.Error(err))
+		return fmt.Errorf("unable to record contract file operation: %v", err)
+	}
+	return nil
+}
+
+// QueuedContractFiles returns up to limit QUEUED entries, highest priority
+// and oldest first, skipping the entries of the excluded jobs.
+func (c *Client) QueuedContractFiles(ctx context.Context, excludeJobs []string, limit int) ([]model.ContractFileEvent, error) {
+	query := fmt.Sprintf("SELECT traceId, contractId, status, timestamp, IFNULL(jobName, '') AS jobName, "+
+		"IFNULL(priority, 0) AS priority, IFNULL(jobRequest, '') AS jobRequest FROM %s "+
+		"WHERE status = @queued AND IFNULL(jobName, '') NOT IN UNNEST(@excludeJobs) "+
+		"ORDER BY priority DESC, timestamp ASC LIMIT @limit", c.queueTable())
+	return c.readContractFiles(ctx, query, []bq.QueryParameter{
+		{Name: "queued", Value: constants.QUEUE_STATUS_QUEUED},
+		{Name: "excludeJobs", Value: append([]string{}, excludeJobs...)},
+		{Name: "limit", Value: limit},
+	})
+}
+
+// DispatchedContractFiles returns the DISPATCHED entries claimed within
+// window. Older entries are no longer counted as running: their execution
+// outlived the tracking timeout and its outcome is unknown.
+func (c *Client) DispatchedContractFiles(ctx context.Context, window time.Duration) ([]model.ContractFileEvent, error) {
+	query := fmt.Sprintf("SELECT traceId, contractId, status, timestamp, IFNULL(jobName, '') AS jobName, "+
+		"IFNULL(operation, '') AS operation FROM %s "+
+		"WHERE status = @dispatched AND dispatchedAt >= TIMESTAMP_SUB(CURRENT_TIMESTAMP(), INTERVAL @windowSeconds SECOND)", c.queueTable())
+	return c.readContractFiles(ctx, query, []bq.QueryParameter{
+		{Name: "dispatched", Value: constants.QUEUE_STATUS_DISPATCHED},
+		{Name: "windowSeconds", Value: int64(window.Seconds())},
+	})
+}
+
+// readContractFiles runs a contract file queue query and loads its rows.
+func (c *Client) readContractFiles(ctx context.Context, query string, params []bq.QueryParameter) ([]model.ContractFileEvent, error) {
+	q := c.client.Query(query)
+	q.Parameters = params
+	it, err := q.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query contract file queue: %v", err)
+	}
+
+	var events []model.ContractFileEvent
+	for {
+		var event model.ContractFileEvent
+		err := it.Next(&event)
+		if err == iterator.Done {
+			return events, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to read contract file queue: %v", err)
+		}
+		events = append(events, event)
+	}
+}
+
+// queueTable returns the fully qualified contract file queue table for queries.
+func (c *Client) queueTable() string {
+	return fmt.Sprintf("`%s.%s.%s`", c.projectId, constants.DATASET_ID, constants.CONTRACT_QUEUE_TABLE)
+}
+
+// runDML runs a parameterized DML statement, waits for it to finish and
+// returns the number of rows it changed. A statement that conflicts with a
+// concurrent update of the table is retried with exponential backoff and
+// jitter, up to DML_ATTEMPTS times.
+func (c *Client) runDML(ctx context.Context, query string, params []bq.QueryParameter) (int64, error) {
+	delay := constants.DML_RETRY_BASE_MS * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		affected, err := c.runDMLOnce(ctx, query, params)
+		if err == nil || !isConcurrentUpdate(err) || attempt == constants.DML_ATTEMPTS {
+			return affected, err
+		}
+
+		c.logger.Info("retrying dml statement after concurrent update",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", c.traceId),
+			zap.Int("attempt", attempt),
+			zap.Error(err))
+		select {
+		case <-time.After(delay + rand.N(delay)):
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+		delay *= 2
+	}
+}
+
+// runDMLOnce runs the statement a single time.
+func (c *Client) runDMLOnce(ctx context.Context, query string, params []bq.QueryParameter) (int64, error) {
+	q := c.client.Query(query)
+	q.Parameters = params
+	job, err := q.Run(ctx)
+	if err != nil {
+		return 0, err
+	}
+	status, err := job.Wait(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err := status.Err(); err != nil {
+		return 0, err
+	}
+	if stats, ok := status.Statistics.Details.(*bq.QueryStatistics); ok {
+		return stats.NumDMLAffectedRows, nil
+	}
+	return 0, nil
+}
+
+// isConcurrentUpdate reports whether a DML statement failed because another
+// statement updated the same table, e.g. "Could not serialize access to table
+// x due to concurrent update".
+func isConcurrentUpdate(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "due to concurrent update")
+}
+
 func (c *Client) Close(ctx context.Context) error {
 	_, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
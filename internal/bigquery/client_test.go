@@ -0,0 +1,25 @@
+package bigquery
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIsConcurrentUpdate(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{err: errors.New("Could not serialize access to table p:d.contract_file_queue due to concurrent update"), want: true},
+		{err: errors.New("Transaction is aborted due to concurrent update against table p:d.t"), want: true},
+		{err: errors.New("Access Denied: Table p:d.t"), want: false},
+		{err: errors.New("Exceeded rate limits: too many table dml insert operations"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			if got := isConcurrentUpdate(tt.err); got != tt.want {
+				t.Errorf("isConcurrentUpdate = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package bigquery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	bq "cloud.google.com/go/bigquery"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+	"go.uber.org/zap"
+	"google.golang.org/api/googleapi"
+)
+
+// ErrSchemaDrift is returned when a table's schema cannot be reconciled with
+// its model struct, or when verifying finds it out of date.
+var ErrSchemaDrift = errors.New("schema drift")
+
+// TableSpec describes a table whose schema is inferred from a model struct.
+// Tables are partitioned by day on PartitionField and clustered on
+// ClusterFields.
+type TableSpec struct {
+	TableId        string
+	Row            any
+	PartitionField string
+	ClusterFields  []string
+}
+
+// Tables are the tables the service writes to.
+var Tables = []TableSpec{
+	{TableId: constants.TABLE_ID, Row: model.AuditEvent{}, PartitionField: "createdTimestamp", ClusterFields: []string{"traceid"}},
+	{TableId: constants.CONTRACT_QUEUE_TABLE, Row: model.ContractFileEvent{}, PartitionField: "timestamp", ClusterFields: []string{"traceId"}},
+}
+
+// InferTableSchema infers the table schema from the row struct's bigquery
+// tags. Every column is nullable, so columns can be added to existing tables
+// and rows can leave them unset.
+func InferTableSchema(row any) (bq.Schema, error) {
+	schema, err := bq.InferSchema(row)
+	if err != nil {
+		return nil, fmt.Errorf("unable to infer schema of %T: %v", row, err)
+	}
+	for _, field := range schema {
+		field.Required = false
+	}
+	return schema, nil
+}
+
+// EnsureSchema checks the dataset and tables against the model structs.
+// With migrate, a missing dataset or table is created and columns missing
+// from a table are added as nullable columns; without it they are reported
+// as errors. A column whose type differs from the struct, or a required
+// column the struct does not write, is incompatible drift and always fails.
+func (c *Client) EnsureSchema(ctx context.Context, migrate bool) error {
+	dataset := c.client.Dataset(constants.DATASET_ID)
+	if _, err := dataset.Metadata(ctx); err != nil {
+		if !isNotFound(err) {
+			return fmt.Errorf("unable to read dataset %s: %v", constants.DATASET_ID, err)
+		}
+		if !migrate {
+			return fmt.Errorf("%w: dataset %s does not exist", ErrSchemaDrift, constants.DATASET_ID)
+		}
+		if err := dataset.Create(ctx, &bq.DatasetMetadata{Location: constants.REGION}); err != nil {
+			return fmt.Errorf("unable to create dataset %s: %v", constants.DATASET_ID, err)
+		}
+		c.logger.Info("created bigquery dataset",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", c.traceId),
+			zap.String("dataset", constants.DATASET_ID))
+	}
+
+	var errs []error
+	for _, spec := range Tables {
+		if err := c.ensureTable(ctx, dataset, spec, migrate); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ensureTable creates the table or brings its columns in line with the spec.
+func (c *Client) ensureTable(ctx context.Context, dataset *bq.Dataset, spec TableSpec, migrate bool) error {
+	schema, err := InferTableSchema(spec.Row)
+	if err != nil {
+		return err
+	}
+
+	table := dataset.Table(spec.TableId)
+	meta, err := table.Metadata(ctx)
+	if err != nil {
+		if !isNotFound(err) {
+			return fmt.Errorf("unable to read table %s: %v", spec.TableId, err)
+		}
+		if !migrate {
+			return fmt.Errorf("%w: table %s does not exist", ErrSchemaDrift, spec.TableId)
+		}
+		err := table.Create(ctx, &bq.TableMetadata{
+			Schema:           schema,
+			TimePartitioning: &bq.TimePartitioning{Type: bq.DayPartitioningType, Field: spec.PartitionField},
+			Clustering:       &bq.Clustering{Fields: spec.ClusterFields},
+		})
+		if err != nil {
+			return fmt.Errorf("unable to create table %s: %v", spec.TableId, err)
+		}
+		c.logger.Info("created bigquery table",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", c.traceId),
+			zap.String("table", spec.TableId))
+		return nil
+	}
+
+	if meta.TimePartitioning == nil || !strings.EqualFold(meta.TimePartitioning.Field, spec.PartitionField) {
+		// Partitioning cannot be changed in place and does not affect writes
+		c.logger.Warn("bigquery table is not partitioned as expected",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", c.traceId),
+			zap.String("table", spec.TableId),
+			zap.String("partitionField", spec.PartitionField))
+	}
+
+	missing, err := diffSchema(spec.TableId, meta.Schema, schema)
+	if err != nil {
+		return err
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	if !migrate {
+		return fmt.Errorf("%w: table %s is missing columns %s", ErrSchemaDrift, spec.TableId, fieldNames(missing))
+	}
+
+	update := bq.TableMetadataToUpdate{Schema: append(meta.Schema, missing...)}
+	if _, err := table.Update(ctx, update, meta.ETag); err != nil {
+		return fmt.Errorf("unable to add columns %s to table %s: %v", fieldNames(missing), spec.TableId, err)
+	}
+	c.logger.Info("added columns to bigquery table",
+		zap.String("applicationName", constants.APPLICATION_NAME),
+		zap.String("traceId", c.traceId),
+		zap.String("table", spec.TableId),
+		zap.String("columns", fieldNames(missing)))
+	return nil
+}
+
+// diffSchema compares the table's columns with the expected ones, matching
+// names case-insensitively like BigQuery does. It returns the expected
+// columns the table lacks, or an error describing the incompatible columns.
+func diffSchema(tableId string, actual bq.Schema, expected bq.Schema) (bq.Schema, error) {
+	columns := map[string]*bq.FieldSchema{}
+	for _, field := range actual {
+		columns[strings.ToLower(field.Name)] = field
+	}
+	expectedNames := map[string]bool{}
+
+	var missing bq.Schema
+	var errs []error
+	for _, field := range expected {
+		expectedNames[strings.ToLower(field.Name)] = true
+		column, ok := columns[strings.ToLower(field.Name)]
+		if !ok {
+			missing = append(missing, field)
+			continue
+		}
+		if column.Type != field.Type || column.Repeated != field.Repeated {
+			errs = append(errs, fmt.Errorf("column %s is %s, expected %s", column.Name, column.Type, field.Type))
+		}
+	}
+	for _, field := range actual {
+		if field.Required && !expectedNames[strings.ToLower(field.Name)] {
+			errs = append(errs, fmt.Errorf("required column %s is not written", field.Name))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%w: table %s is incompatible: %w", ErrSchemaDrift, tableId, errors.Join(errs...))
+	}
+	return missing, nil
+}
+
+// fieldNames lists the schema's column names.
+func fieldNames(schema bq.Schema) string {
+	names := make([]string, len(schema))
+	for i, field := range schema {
+		names[i] = field.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// isNotFound reports whether the API error is a 404.
+func isNotFound(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound
+}
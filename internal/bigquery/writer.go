@@ -0,0 +1,224 @@
+package bigquery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	bq "cloud.google.com/go/bigquery"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrAuditBufferFull is returned when an event is dropped because the
+	// writer's buffer is full.
+	ErrAuditBufferFull = errors.New("audit buffer is full")
+
+	// ErrAuditWriterClosed is returned when an event is logged after Close.
+	ErrAuditWriterClosed = errors.New("audit writer is closed")
+)
+
+// AuditLogger receives audit events. It is implemented by the audit sinks.
+type AuditLogger interface {
+	LogAuditData(ctx context.Context, event model.AuditEvent) error
+}
+
+// AuditWriterConfig sizes the buffered audit writer.
+type AuditWriterConfig struct {
+	BufferSize    int           // events held in memory before new ones are dropped
+	BatchSize     int           // events written per insert
+	FlushInterval time.Duration // maximum time an event waits before it is written
+
+	// Fallback, when set, receives the events BigQuery rejected.
+	Fallback AuditLogger
+}
+
+// AuditWriter buffers audit events in memory and inserts them into the audit
+// table in batches from a background goroutine, so logging an event never
+// waits on BigQuery. A batch is written once it is full or the flush interval
+// passes, and the remaining events are written on Close.
+type AuditWriter struct {
+	logger   *zap.Logger
+	client   *bq.Client
+	inserter *bq.Inserter
+	config   AuditWriterConfig
+
+	mu     sync.RWMutex
+	closed bool
+	events chan model.AuditEvent
+	done   chan struct{}
+
+	enqueued atomic.Int64
+	written  atomic.Int64
+	dropped  atomic.Int64
+	failed   atomic.Int64
+}
+
+// NewAuditWriter creates a BigQuery client for the project and starts the
+// background writer. The writer outlives the requests that log to it and
+// must be closed to write the events still buffered.
+func NewAuditWriter(ctx context.Context, logger *zap.Logger, projectId string, config AuditWriterConfig) (*AuditWriter, error) {
+	client, err := bq.NewClient(ctx, projectId)
+	if err != nil {
+		logger.Error("unable to create bigquery client",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.Error(err))
+		return nil, fmt.Errorf("unable to create bigquery client: %v", err)
+	}
+
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = constants.DEFAULT_AUDIT_FLUSH_MS * time.Millisecond
+	}
+	w := &AuditWriter{
+		logger:   logger,
+		client:   client,
+		inserter: client.Dataset(constants.DATASET_ID).Table(constants.TABLE_ID).Inserter(),
+		config:   config,
+		events:   make(chan model.AuditEvent, max(config.BufferSize, 1)),
+		done:     make(chan struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+// LogAuditData buffers the event without blocking. When the buffer is full
+// the event is dropped, counted and ErrAuditBufferFull is returned.
+func (w *AuditWriter) LogAuditData(ctx context.Context, event model.AuditEvent) error {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		w.dropped.Add(1)
+		return ErrAuditWriterClosed
+	}
+
+	select {
+	case w.events <- event:
+		w.enqueued.Add(1)
+		return nil
+	default:
+		w.dropped.Add(1)
+		w.logger.Warn("audit buffer full, dropping event",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", event.TraceID),
+			zap.String("event", event.Event))
+		return ErrAuditBufferFull
+	}
+}
+
+// Stats returns the writer's counters.
+func (w *AuditWriter) Stats() model.AuditStats {
+	return model.AuditStats{
+		Enqueued: w.enqueued.Load(),
+		Written:  w.written.Load(),
+		Dropped:  w.dropped.Load(),
+		Failed:   w.failed.Load(),
+	}
+}
+
+// Close stops accepting events, writes the buffered ones and closes the
+// BigQuery client. Events still buffered when ctx is done are lost.
+func (w *AuditWriter) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.events)
+	}
+	w.mu.Unlock()
+
+	select {
+	case <-w.done:
+	case <-ctx.Done():
+		w.logger.Error("audit writer closed before flushing",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.Int("pending", len(w.events)),
+			zap.Error(ctx.Err()))
+		return ctx.Err()
+	}
+
+	stats := w.Stats()
+	w.logger.Info("audit writer closed",
+		zap.String("applicationName", constants.APPLICATION_NAME),
+		zap.Int64("written", stats.Written),
+		zap.Int64("dropped", stats.Dropped),
+		zap.Int64("failed", stats.Failed))
+
+	if err := w.client.Close(); err != nil {
+		return fmt.Errorf("unable to close bigquery client: %v", err)
+	}
+	return nil
+}
+
+// run collects events into batches until the events channel is closed.
+func (w *AuditWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+
+	batchSize := max(w.config.BatchSize, 1)
+	batch := make([]*model.AuditEvent, 0, batchSize)
+	for {
+		select {
+		case event, ok := <-w.events:
+			if !ok {
+				w.write(batch)
+				return
+			}
+			batch = append(batch, &event)
+			if len(batch) >= batchSize {
+				batch = w.write(batch)
+			}
+		case <-ticker.C:
+			batch = w.write(batch)
+		}
+	}
+}
+
+// write inserts the batch and returns it emptied for reuse. Rows rejected
+// by BigQuery are counted as failed and handed to the fallback, if any,
+// instead of being retried.
+func (w *AuditWriter) write(batch []*model.AuditEvent) []*model.AuditEvent {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), constants.AUDIT_WRITE_TIMEOUT_SECONDS*time.Second)
+	defer cancel()
+
+	var rejected []*model.AuditEvent
+	if err := w.inserter.Put(ctx, batch); err != nil {
+		var rowErrs bq.PutMultiError
+		if errors.As(err, &rowErrs) {
+			for _, rowErr := range rowErrs {
+				rejected = append(rejected, batch[rowErr.RowIndex])
+			}
+		} else {
+			rejected = batch
+		}
+		w.logger.Error("unable to persist audit events into bigquery",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.Int("batchSize", len(batch)),
+			zap.Int("failed", len(rejected)),
+			zap.Error(err))
+	}
+	w.failed.Add(int64(len(rejected)))
+	w.written.Add(int64(len(batch) - len(rejected)))
+
+	if w.config.Fallback != nil {
+		for _, event := range rejected {
+			w.config.Fallback.LogAuditData(ctx, *event)
+		}
+	}
+
+	clear(batch)
+	return batch[:0]
+}
@@ -5,11 +5,16 @@ package compute
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
 	"time"
 
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
 	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/durationpb"
 
 	run "cloud.google.com/go/run/apiv2"
 	"cloud.google.com/go/run/apiv2/runpb"
@@ -41,27 +46,24 @@ func NewCompute(ctx context.Context, logger *zap.Logger, traceId string) (*Compu
 }
 
 // TriggerFileStreamerJob starts a Cloud Run job using the provided project,
-// region, job name, and arguments. It logs both the initiation and result
-// of the operation for observability and debugging.
-func (c *Compute) TriggerFileStreamerJob(ctx context.Context, projectId string, region string, jobName string, args []string) error {
+// region, job name, and arguments. The optional profile overrides the
+// container environment, task timeout and task count of the execution, and
+// the optional shard manifest runs one task per byte-range shard. It
+// logs both the initiation and result of the operation for observability and
+// debugging, and returns the name of the long-running operation and, when
+// already known, of the execution.
+func (c *Compute) TriggerFileStreamerJob(ctx context.Context, projectId string, region string, jobName string, args []string, profile *model.ResourceProfile, shards *model.ShardManifest) (string, string, error) {
 	name := fmt.Sprintf(constants.JOB_PREFIX, projectId, region, jobName)
 	c.logger.Info("attempting to trigger cloud run job",
 		zap.String("applicationName", constants.APPLICATION_NAME),
 		zap.String("traceId", c.traceId),
 		zap.String("region", region),
-		zap.String("jobName", constants.CLOUD_RUN_JOB_NAME),
+		zap.String("jobName", jobName),
 		zap.String("name", name))
 
 	req := &runpb.RunJobRequest{
-		Name: name,
-		Overrides: &runpb.RunJobRequest_Overrides{
-			ContainerOverrides: []*runpb.RunJobRequest_Overrides_ContainerOverride{
-				{
-					Args: args,
-				},
-			},
-			TaskCount: 1,
-		},
+		Name:      name,
+		Overrides: runOverrides(args, profile, shards),
 	}
 
 	op, err := c.client.RunJob(ctx, req)
@@ -71,15 +73,108 @@ func (c *Compute) TriggerFileStreamerJob(ctx context.Context, projectId string,
 			zap.String("traceId", c.traceId),
 			zap.Error(err))
 
-		return err
+		return "", "", err
+	}
+
+	var executionName string
+	if execution, err := op.Metadata(); err == nil && execution != nil {
+		executionName = execution.GetName()
 	}
 
 	c.logger.Info("triggered cloud run job",
 		zap.String("applicationName", constants.APPLICATION_NAME),
 		zap.String("traceId", c.traceId),
-		zap.String("jobName", op.Name()))
+		zap.String("jobName", op.Name()),
+		zap.String("execution", executionName))
 
-	return nil
+	return op.Name(), executionName, nil
+}
+
+// runOverrides builds the execution overrides: the arguments, plus the env,
+// timeout and task count of the profile. A single task is run by default;
+// a shard manifest runs one task per shard and is passed in SHARD_MANIFEST.
+func runOverrides(args []string, profile *model.ResourceProfile, shards *model.ShardManifest) *runpb.RunJobRequest_Overrides {
+	container := &runpb.RunJobRequest_Overrides_ContainerOverride{Args: args}
+	overrides := &runpb.RunJobRequest_Overrides{
+		ContainerOverrides: []*runpb.RunJobRequest_Overrides_ContainerOverride{container},
+		TaskCount:          1,
+	}
+
+	if profile != nil {
+		for _, name := range slices.Sorted(maps.Keys(profile.Env)) {
+			container.Env = append(container.Env, envVar(name, profile.Env[name]))
+		}
+		if profile.TimeoutSeconds > 0 {
+			overrides.Timeout = durationpb.New(time.Duration(profile.TimeoutSeconds) * time.Second)
+		}
+		if profile.TaskCount > 0 {
+			overrides.TaskCount = profile.TaskCount
+		}
+	}
+
+	if shards != nil {
+		manifest, _ := json.Marshal(shards)
+		container.Env = append(container.Env, envVar(constants.SHARD_MANIFEST_ENV, string(manifest)))
+		overrides.TaskCount = int32(len(shards.Shards))
+	}
+	return overrides
+}
+
+func envVar(name string, value string) *runpb.EnvVar {
+	return &runpb.EnvVar{Name: name, Values: &runpb.EnvVar_Value{Value: value}}
+}
+
+// WaitExecution waits for the execution started by the named RunJob operation
+// to finish and reports its terminal state and task counts. An error is
+// returned when the operation has not finished, for example because the
+// context deadline passed first.
+func (c *Compute) WaitExecution(ctx context.Context, operation string) (model.ExecutionOutcome, error) {
+	outcome := model.ExecutionOutcome{Operation: operation}
+
+	op := c.client.RunJobOperation(operation)
+	execution, err := op.Wait(ctx)
+	if err != nil && !op.Done() {
+		c.logger.Warn("cloud run execution not finished",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", c.traceId),
+			zap.String("operation", operation),
+			zap.Error(err))
+		return outcome, err
+	}
+	if err != nil {
+		// The operation finished with an error: the execution failed or was
+		// cancelled and the latest metadata carries its counts
+		outcome.Message = err.Error()
+		execution, _ = op.Metadata()
+	}
+
+	if execution != nil {
+		outcome.Execution = execution.GetName()
+		outcome.TaskCount = execution.GetTaskCount()
+		outcome.SucceededCount = execution.GetSucceededCount()
+		outcome.FailedCount = execution.GetFailedCount()
+		outcome.CancelledCount = execution.GetCancelledCount()
+		outcome.RetriedCount = execution.GetRetriedCount()
+	}
+
+	switch {
+	case err == nil:
+		outcome.State = constants.EXECUTION_SUCCEEDED
+	case outcome.CancelledCount > 0 && outcome.FailedCount == 0:
+		outcome.State = constants.EXECUTION_CANCELLED
+	default:
+		outcome.State = constants.EXECUTION_FAILED
+	}
+
+	c.logger.Info("cloud run execution finished",
+		zap.String("applicationName", constants.APPLICATION_NAME),
+		zap.String("traceId", c.traceId),
+		zap.String("operation", operation),
+		zap.String("execution", outcome.Execution),
+		zap.String("state", outcome.State),
+		zap.Int32("failedCount", outcome.FailedCount))
+
+	return outcome, nil
 }
 
 // Close gracefully closes the Cloud Run JobsClient to free resources.
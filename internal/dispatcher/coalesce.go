@@ -0,0 +1,41 @@
+package dispatcher
+
+import "sync"
+
+// Coalescer runs at most one dispatch pass at a time. Passes requested while
+// one is running are folded into a single follow-up pass, so a burst of
+// finished executions does not start a chain of overlapping passes. The zero
+// value is ready to use and is meant to be shared by every request of an
+// instance.
+type Coalescer struct {
+	mu      sync.Mutex
+	running bool
+	pending bool
+}
+
+// Run runs pass unless another pass is in flight, in which case it only
+// records that one more pass is needed and returns. The caller that started
+// the running pass runs the follow-up once its pass returns.
+func (c *Coalescer) Run(pass func()) {
+	c.mu.Lock()
+	if c.running {
+		c.pending = true
+		c.mu.Unlock()
+		return
+	}
+	c.running = true
+	c.mu.Unlock()
+
+	for {
+		pass()
+
+		c.mu.Lock()
+		if !c.pending {
+			c.running = false
+			c.mu.Unlock()
+			return
+		}
+		c.pending = false
+		c.mu.Unlock()
+	}
+}
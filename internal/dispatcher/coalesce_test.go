@@ -0,0 +1,67 @@
+package dispatcher
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCoalescer(t *testing.T) {
+	tests := []struct {
+		name     string
+		requests int // passes requested while the first one runs
+		passes   int32
+	}{
+		{name: "single pass", requests: 0, passes: 1},
+		{name: "one follow-up", requests: 1, passes: 2},
+		{name: "burst folded into one follow-up", requests: 20, passes: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Coalescer
+			var passes atomic.Int32
+			started := make(chan struct{})
+			release := make(chan struct{})
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				c.Run(func() {
+					if passes.Add(1) == 1 {
+						close(started)
+						<-release
+					}
+				})
+			}()
+
+			<-started
+			var wg sync.WaitGroup
+			for range tt.requests {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					// Returns at once, the running caller does the work
+					c.Run(func() { passes.Add(1) })
+				}()
+			}
+			wg.Wait()
+			close(release)
+			<-done
+
+			if got := passes.Load(); got != tt.passes {
+				t.Errorf("ran %d passes, want %d", got, tt.passes)
+			}
+		})
+	}
+}
+
+func TestCoalescerRunsAgainAfterIdle(t *testing.T) {
+	var c Coalescer
+	count := 0
+	for range 3 {
+		c.Run(func() { count++ })
+	}
+	if count != 3 {
+		t.Errorf("ran %d passes, want 3", count)
+	}
+}
@@ -0,0 +1,320 @@
+// Package dispatcher launches the jobs of files left QUEUED in the contract
+// file queue. Each run settles the executions that have finished, counts the
+// ones still running per job and claims queued entries, highest priority and
+// oldest first, only while their job stays below its concurrency limit. The
+// limit is enforced by the claim itself, so concurrent runs cannot exceed it.
+package dispatcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/launcher"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+	"go.uber.org/zap"
+)
+
+// Queue reads and updates the contract file queue. It is implemented by
+// *bigquery.Client.
+type Queue interface {
+	QueuedContractFiles(ctx context.Context, excludeJobs []string, limit int) ([]model.ContractFileEvent, error)
+	DispatchedContractFiles(ctx context.Context, window time.Duration) ([]model.ContractFileEvent, error)
+	ClaimContractFile(ctx context.Context, traceId string, contractId string, jobName string, maxRunning int, window time.Duration) (bool, error)
+	SetContractFileOperation(ctx context.Context, traceId string, contractId string, operation string) error
+	UpdateContractFileStatus(ctx context.Context, traceId string, contractId string, status string) error
+}
+
+// AuditLogger writes audit events. It is implemented by the audit sinks.
+type AuditLogger interface {
+	LogAuditData(ctx context.Context, event model.AuditEvent) error
+}
+
+// ExecutionWaiter waits for a launched execution to finish.
+type ExecutionWaiter interface {
+	WaitExecution(ctx context.Context, operation string) (model.ExecutionOutcome, error)
+}
+
+// ExecutionTracker follows a launched execution to its terminal state.
+type ExecutionTracker interface {
+	Track(ctx context.Context, fileUrl string, operation string, onDone func(outcome model.ExecutionOutcome))
+}
+
+// Limits bounds the dispatcher. It is implemented by *routing.Router.
+type Limits interface {
+	MaxRunning(jobName string) int
+	DispatchBatchSize() int
+}
+
+// Dispatcher launches queued contract files within the per-job limits.
+type Dispatcher struct {
+	logger   *zap.Logger
+	traceId  string
+	queue    Queue
+	audit    AuditLogger
+	launcher launcher.JobLauncher
+	waiter   ExecutionWaiter
+	tracker  ExecutionTracker
+	limits   Limits
+	window   time.Duration
+	passes   *Coalescer
+}
+
+// NewDispatcher creates a dispatcher. Entries dispatched longer than window
+// ago, the execution tracking timeout, no longer count as running. The passes
+// started by Redispatch are coalesced through passes.
+func NewDispatcher(logger *zap.Logger, traceId string, queue Queue, audit AuditLogger, launcher launcher.JobLauncher, waiter ExecutionWaiter, tracker ExecutionTracker, limits Limits, window time.Duration, passes *Coalescer) *Dispatcher {
+	return &Dispatcher{
+		logger:   logger,
+		traceId:  traceId,
+		queue:    queue,
+		audit:    audit,
+		launcher: launcher,
+		waiter:   waiter,
+		tracker:  tracker,
+		limits:   limits,
+		window:   window,
+		passes:   passes,
+	}
+}
+
+// Dispatch runs one dispatch pass. Entries are claimed with a conditional
+// update, which also checks the job's running count, before their job is
+// launched, so concurrent passes never launch the same entry twice nor run
+// more than MaxRunning executions of a job.
+func (d *Dispatcher) Dispatch(ctx context.Context) (model.DispatchResponse, error) {
+	response := model.DispatchResponse{TraceId: d.traceId, Running: map[string]int{}, Results: []model.DispatchResult{}}
+
+	dispatched, err := d.queue.DispatchedContractFiles(ctx, d.window)
+	if err != nil {
+		return response, err
+	}
+	running, reconciled := d.reconcile(ctx, dispatched)
+	response.Reconciled = reconciled
+	for _, entry := range running {
+		response.Running[entry.JobName]++
+	}
+
+	var full []string
+	for jobName, count := range response.Running {
+		if count >= d.limits.MaxRunning(jobName) {
+			full = append(full, jobName)
+		}
+	}
+	queued, err := d.queue.QueuedContractFiles(ctx, full, d.limits.DispatchBatchSize())
+	if err != nil {
+		return response, err
+	}
+
+	for _, entry := range queued {
+		maxRunning := d.limits.MaxRunning(entry.JobName)
+		if response.Running[entry.JobName] >= maxRunning {
+			continue
+		}
+		claimed, err := d.queue.ClaimContractFile(ctx, entry.TraceID, entry.ContractID, entry.JobName, maxRunning, d.window)
+		if err != nil {
+			// The entry stays QUEUED for the next pass
+			d.claimFailed(ctx, entry, err)
+			continue
+		}
+		if !claimed {
+			// Another dispatcher took the entry or filled the job's last
+			// slot; skip the job's other entries in this pass
+			response.Running[entry.JobName] = max(response.Running[entry.JobName], maxRunning)
+			continue
+		}
+
+		result := d.launch(ctx, entry)
+		if result.Status == constants.QUEUE_STATUS_DISPATCHED {
+			response.Running[entry.JobName]++
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	d.logger.Info("dispatch completed",
+		zap.String("applicationName", constants.APPLICATION_NAME),
+		zap.String("traceId", d.traceId),
+		zap.Int("reconciled", response.Reconciled),
+		zap.Int("dispatched", len(response.Results)),
+		zap.Any("running", response.Running))
+
+	return response, nil
+}
+
+// reconcile checks the dispatched entries with a known operation, each for
+// a few seconds, and settles those whose execution has finished. It returns
+// the entries still running and how many were settled.
+func (d *Dispatcher) reconcile(ctx context.Context, dispatched []model.ContractFileEvent) ([]model.ContractFileEvent, int) {
+	finished := make([]bool, len(dispatched))
+	var wg sync.WaitGroup
+	for i, entry := range dispatched {
+		if entry.Operation == "" {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			waitCtx, cancel := context.WithTimeout(ctx, constants.DISPATCH_RECONCILE_SECONDS*time.Second)
+			defer cancel()
+			outcome, err := d.waiter.WaitExecution(waitCtx, entry.Operation)
+			if err != nil {
+				return
+			}
+			finished[i] = d.settle(ctx, entry, outcome)
+		}()
+	}
+	wg.Wait()
+
+	var running []model.ContractFileEvent
+	reconciled := 0
+	for i, entry := range dispatched {
+		if finished[i] {
+			reconciled++
+		} else {
+			running = append(running, entry)
+		}
+	}
+	return running, reconciled
+}
+
+// launch starts the job stored with a claimed entry and records the result.
+func (d *Dispatcher) launch(ctx context.Context, entry model.ContractFileEvent) model.DispatchResult {
+	result := model.DispatchResult{TraceId: entry.TraceID, ContractId: entry.ContractID, JobName: entry.JobName}
+
+	var req launcher.JobRequest
+	err := json.Unmarshal([]byte(entry.JobRequest), &req)
+	if err == nil && req.JobName == "" {
+		err = fmt.Errorf("queue entry has no job request")
+	}
+	var job launcher.JobResult
+	if err == nil {
+		job, err = d.launcher.Launch(ctx, req)
+	}
+	if err != nil {
+		d.logger.Error("unable to dispatch contract file",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", d.traceId),
+			zap.String("contractId", entry.ContractID),
+			zap.String("jobName", entry.JobName),
+			zap.Error(err))
+		if err := d.queue.UpdateContractFileStatus(ctx, entry.TraceID, entry.ContractID, constants.QUEUE_STATUS_FAILED); err != nil {
+			d.updateFailed(ctx, req.FileUrl, entry, err)
+		}
+		result.Status = constants.QUEUE_STATUS_FAILED
+		result.Error = err.Error()
+		d.record(ctx, req.FileUrl, constants.CONTRACT_FILE_DISPATCH_FAILED, constants.FAILED, result)
+		return result
+	}
+
+	result.Status = constants.QUEUE_STATUS_DISPATCHED
+	result.Operation = job.Operation
+	if job.Operation != "" {
+		if err := d.queue.SetContractFileOperation(ctx, entry.TraceID, entry.ContractID, job.Operation); err != nil {
+			// Without its operation the entry cannot be reconciled and counts
+			// as running until the window passes
+			d.updateFailed(ctx, req.FileUrl, entry, err)
+		}
+	}
+	d.record(ctx, req.FileUrl, constants.CONTRACT_FILE_DISPATCHED, constants.COMPLETED, result)
+
+	if d.tracker != nil && job.Operation != "" {
+		d.tracker.Track(ctx, req.FileUrl, job.Operation, func(outcome model.ExecutionOutcome) {
+			ctx := context.WithoutCancel(ctx)
+			if d.settle(ctx, entry, outcome) {
+				// The job has a free slot again
+				d.Redispatch(ctx)
+			}
+		})
+	}
+	return result
+}
+
+// Redispatch runs another pass to launch the entries waiting for a slot
+// freed by a finished execution. While a pass is in flight the request is
+// folded into one follow-up pass instead of starting another.
+func (d *Dispatcher) Redispatch(ctx context.Context) {
+	d.passes.Run(func() {
+		if _, err := d.Dispatch(ctx); err != nil {
+			d.logger.Error("dispatch after settled execution failed",
+				zap.String("applicationName", constants.APPLICATION_NAME),
+				zap.String("traceId", d.traceId),
+				zap.Error(err))
+		}
+	})
+}
+
+// settle moves the entry to DONE or FAILED once its execution has reached a
+// terminal state. It reports whether the entry was settled.
+func (d *Dispatcher) settle(ctx context.Context, entry model.ContractFileEvent, outcome model.ExecutionOutcome) bool {
+	status := constants.QUEUE_STATUS_FAILED
+	switch outcome.State {
+	case constants.EXECUTION_SUCCEEDED:
+		status = constants.QUEUE_STATUS_DONE
+	case constants.EXECUTION_FAILED, constants.EXECUTION_CANCELLED:
+	default:
+		return false
+	}
+	if err := d.queue.UpdateContractFileStatus(ctx, entry.TraceID, entry.ContractID, status); err != nil {
+		d.updateFailed(ctx, "", entry, err)
+		return false
+	}
+	return true
+}
+
+// claimFailed logs and audits a claim that failed, which leaves the entry
+// QUEUED for the next pass.
+func (d *Dispatcher) claimFailed(ctx context.Context, entry model.ContractFileEvent, err error) {
+	d.logger.Error("unable to claim contract file queue entry",
+		zap.String("applicationName", constants.APPLICATION_NAME),
+		zap.String("traceId", d.traceId),
+		zap.String("contractId", entry.ContractID),
+		zap.String("jobName", entry.JobName),
+		zap.Error(err))
+	d.audit.LogAuditData(ctx, model.AuditEvent{
+		TraceID:      d.traceId,
+		ContractId:   entry.ContractID,
+		Event:        constants.CONTRACT_QUEUE_UPDATE_FAILED,
+		Status:       constants.FAILED,
+		Timestamp:    time.Now(),
+		FunctionName: constants.APPLICATION_NAME,
+		Message:      err.Error(),
+	})
+}
+
+// updateFailed logs and audits a queue update that failed, which leaves the
+// entry DISPATCHED until the window passes.
+func (d *Dispatcher) updateFailed(ctx context.Context, fileUrl string, entry model.ContractFileEvent, err error) {
+	d.logger.Error("unable to update contract file queue entry",
+		zap.String("applicationName", constants.APPLICATION_NAME),
+		zap.String("traceId", d.traceId),
+		zap.String("contractId", entry.ContractID),
+		zap.Error(err))
+	d.audit.LogAuditData(ctx, model.AuditEvent{
+		TraceID:      d.traceId,
+		ContractId:   entry.ContractID,
+		Event:        constants.CONTRACT_QUEUE_UPDATE_FAILED,
+		Status:       constants.FAILED,
+		Timestamp:    time.Now(),
+		FileUrl:      fileUrl,
+		FunctionName: constants.APPLICATION_NAME,
+		Message:      err.Error(),
+	})
+}
+
+// record writes the audit event for a dispatched entry.
+func (d *Dispatcher) record(ctx context.Context, fileUrl string, event string, status string, result model.DispatchResult) {
+	message, _ := json.Marshal(result)
+	d.audit.LogAuditData(ctx, model.AuditEvent{
+		TraceID:      d.traceId,
+		ContractId:   result.ContractId,
+		Event:        event,
+		FileUrl:      fileUrl,
+		Status:       status,
+		Timestamp:    time.Now(),
+		FunctionName: constants.APPLICATION_NAME,
+		Message:      string(message),
+	})
+}
@@ -0,0 +1,300 @@
+package dispatcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/launcher"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+	"go.uber.org/zap"
+)
+
+// fakeQueue is an in-memory contract file queue whose claim, like the
+// BigQuery one, is atomic and checks the job's running count.
+type fakeQueue struct {
+	mu       sync.Mutex
+	entries  []model.ContractFileEvent
+	claimErr error
+}
+
+func (q *fakeQueue) QueuedContractFiles(ctx context.Context, excludeJobs []string, limit int) ([]model.ContractFileEvent, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var queued []model.ContractFileEvent
+	for _, entry := range q.entries {
+		if entry.Status == constants.QUEUE_STATUS_QUEUED && !slices.Contains(excludeJobs, entry.JobName) && len(queued) < limit {
+			queued = append(queued, entry)
+		}
+	}
+	return queued, nil
+}
+
+func (q *fakeQueue) DispatchedContractFiles(ctx context.Context, window time.Duration) ([]model.ContractFileEvent, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var dispatched []model.ContractFileEvent
+	for _, entry := range q.entries {
+		if entry.Status == constants.QUEUE_STATUS_DISPATCHED {
+			dispatched = append(dispatched, entry)
+		}
+	}
+	return dispatched, nil
+}
+
+func (q *fakeQueue) ClaimContractFile(ctx context.Context, traceId string, contractId string, jobName string, maxRunning int, window time.Duration) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.claimErr != nil {
+		return false, q.claimErr
+	}
+	if q.running(jobName) >= maxRunning {
+		return false, nil
+	}
+	entry := q.find(traceId, contractId)
+	if entry.Status != constants.QUEUE_STATUS_QUEUED {
+		return false, nil
+	}
+	entry.Status = constants.QUEUE_STATUS_DISPATCHED
+	entry.DispatchedAt = time.Now()
+	return true, nil
+}
+
+func (q *fakeQueue) SetContractFileOperation(ctx context.Context, traceId string, contractId string, operation string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.find(traceId, contractId).Operation = operation
+	return nil
+}
+
+func (q *fakeQueue) UpdateContractFileStatus(ctx context.Context, traceId string, contractId string, status string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.find(traceId, contractId).Status = status
+	return nil
+}
+
+func (q *fakeQueue) running(jobName string) int {
+	count := 0
+	for _, entry := range q.entries {
+		if entry.JobName == jobName && entry.Status == constants.QUEUE_STATUS_DISPATCHED {
+			count++
+		}
+	}
+	return count
+}
+
+func (q *fakeQueue) find(traceId string, contractId string) *model.ContractFileEvent {
+	for i := range q.entries {
+		if q.entries[i].TraceID == traceId && q.entries[i].ContractID == contractId {
+			return &q.entries[i]
+		}
+	}
+	panic("unknown queue entry " + contractId)
+}
+
+// statuses counts the entries of each job per status.
+func (q *fakeQueue) statuses(jobName string) map[string]int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	counts := map[string]int{}
+	for _, entry := range q.entries {
+		if entry.JobName == jobName {
+			counts[entry.Status]++
+		}
+	}
+	return counts
+}
+
+// fakeAudit records the audit event names.
+type fakeAudit struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (a *fakeAudit) LogAuditData(ctx context.Context, event model.AuditEvent) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events = append(a.events, event.Event)
+	return nil
+}
+
+func (a *fakeAudit) has(event string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return slices.Contains(a.events, event)
+}
+
+// fakeWaiter reports the listed operations in their state and every other
+// one as still running.
+type fakeWaiter map[string]string
+
+func (w fakeWaiter) WaitExecution(ctx context.Context, operation string) (model.ExecutionOutcome, error) {
+	state, ok := w[operation]
+	if !ok {
+		return model.ExecutionOutcome{Operation: operation, State: "running"}, nil
+	}
+	return model.ExecutionOutcome{Operation: operation, State: state}, nil
+}
+
+type fakeLimits map[string]int
+
+func (l fakeLimits) MaxRunning(jobName string) int { return l[jobName] }
+func (l fakeLimits) DispatchBatchSize() int        { return 100 }
+
+// entries builds count entries for the job in the given status.
+func entries(jobName string, status string, count int) []model.ContractFileEvent {
+	request, _ := json.Marshal(launcher.JobRequest{FileUrl: "https://example.com/" + jobName, JobName: jobName})
+	var built []model.ContractFileEvent
+	for i := range count {
+		built = append(built, model.ContractFileEvent{
+			TraceID:    "trace",
+			ContractID: fmt.Sprintf("%s-%s-%d", jobName, status, i),
+			Status:     status,
+			JobName:    jobName,
+			JobRequest: string(request),
+			Operation:  fmt.Sprintf("operations/%s-%s-%d", jobName, status, i),
+		})
+	}
+	return built
+}
+
+func TestDispatchEnforcesMaxRunning(t *testing.T) {
+	tests := []struct {
+		name     string
+		entries  []model.ContractFileEvent
+		limits   fakeLimits
+		waiter   fakeWaiter
+		passes   int // concurrent dispatch passes
+		launched map[string]int
+	}{
+		{
+			name:     "queue below limit",
+			entries:  entries("a", constants.QUEUE_STATUS_QUEUED, 2),
+			limits:   fakeLimits{"a": 5},
+			passes:   1,
+			launched: map[string]int{"a": 2},
+		},
+		{
+			name:     "queue above limit",
+			entries:  entries("a", constants.QUEUE_STATUS_QUEUED, 10),
+			limits:   fakeLimits{"a": 3},
+			passes:   1,
+			launched: map[string]int{"a": 3},
+		},
+		{
+			name:     "running executions count",
+			entries:  slices.Concat(entries("a", constants.QUEUE_STATUS_DISPATCHED, 2), entries("a", constants.QUEUE_STATUS_QUEUED, 5)),
+			limits:   fakeLimits{"a": 3},
+			passes:   1,
+			launched: map[string]int{"a": 1},
+		},
+		{
+			name:     "job at limit skipped",
+			entries:  slices.Concat(entries("a", constants.QUEUE_STATUS_DISPATCHED, 2), entries("a", constants.QUEUE_STATUS_QUEUED, 5), entries("b", constants.QUEUE_STATUS_QUEUED, 2)),
+			limits:   fakeLimits{"a": 2, "b": 4},
+			passes:   1,
+			launched: map[string]int{"b": 2},
+		},
+		{
+			name:     "finished executions free slots",
+			entries:  slices.Concat(entries("a", constants.QUEUE_STATUS_DISPATCHED, 2), entries("a", constants.QUEUE_STATUS_QUEUED, 5)),
+			limits:   fakeLimits{"a": 2},
+			waiter:   fakeWaiter{"operations/a-DISPATCHED-0": constants.EXECUTION_SUCCEEDED},
+			passes:   1,
+			launched: map[string]int{"a": 1},
+		},
+		{
+			name:     "concurrent passes",
+			entries:  slices.Concat(entries("a", constants.QUEUE_STATUS_QUEUED, 20), entries("b", constants.QUEUE_STATUS_QUEUED, 20)),
+			limits:   fakeLimits{"a": 3, "b": 1},
+			passes:   8,
+			launched: map[string]int{"a": 3, "b": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := &fakeQueue{entries: tt.entries}
+			memory := launcher.NewMemory()
+
+			var wg sync.WaitGroup
+			for range tt.passes {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					d := NewDispatcher(zap.NewNop(), "trace", queue, &fakeAudit{}, memory, tt.waiter, nil, tt.limits, time.Hour, &Coalescer{})
+					if _, err := d.Dispatch(context.Background()); err != nil {
+						t.Error(err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			launched := map[string]int{}
+			for _, req := range memory.Requests() {
+				launched[req.JobName]++
+			}
+			for jobName, limit := range tt.limits {
+				if launched[jobName] != tt.launched[jobName] {
+					t.Errorf("launched %d %s jobs, want %d", launched[jobName], jobName, tt.launched[jobName])
+				}
+				if running := queue.statuses(jobName)[constants.QUEUE_STATUS_DISPATCHED]; running > limit {
+					t.Errorf("%d %s executions running, limit is %d", running, jobName, limit)
+				}
+			}
+		})
+	}
+}
+
+func TestDispatchFailures(t *testing.T) {
+	tests := []struct {
+		name      string
+		claimErr  error
+		launchErr error
+		status    string
+		event     string
+	}{
+		{
+			name:     "claim error leaves entry queued",
+			claimErr: errors.New("could not serialize access due to concurrent update"),
+			status:   constants.QUEUE_STATUS_QUEUED,
+			event:    constants.CONTRACT_QUEUE_UPDATE_FAILED,
+		},
+		{
+			name:      "launch error fails entry",
+			launchErr: errors.New("quota exceeded"),
+			status:    constants.QUEUE_STATUS_FAILED,
+			event:     constants.CONTRACT_FILE_DISPATCH_FAILED,
+		},
+		{name: "launched", status: constants.QUEUE_STATUS_DISPATCHED, event: constants.CONTRACT_FILE_DISPATCHED},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := &fakeQueue{entries: entries("a", constants.QUEUE_STATUS_QUEUED, 1), claimErr: tt.claimErr}
+			memory := launcher.NewMemory()
+			memory.Err = tt.launchErr
+
+			audit := &fakeAudit{}
+			d := NewDispatcher(zap.NewNop(), "trace", queue, audit, memory, fakeWaiter{}, nil, fakeLimits{"a": 1}, time.Hour, &Coalescer{})
+			if _, err := d.Dispatch(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if got := queue.statuses("a"); got[tt.status] != 1 {
+				t.Errorf("statuses = %v, want one %s", got, tt.status)
+			}
+			if !audit.has(tt.event) {
+				t.Errorf("audit events = %v, want %s", audit.events, tt.event)
+			}
+		})
+	}
+}
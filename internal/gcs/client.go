@@ -89,6 +89,35 @@ func (c *GCSClient) CheckAlreadyProcessed(fileInfo model.FileInfo, ctx context.C
 	return true, nil
 }
 
+// WriteObject uploads data to the object path and returns its gs:// URI.
+func (c *GCSClient) WriteObject(ctx context.Context, objectPath string, data []byte, contentType string) (string, error) {
+	uri := fmt.Sprintf("gs://%s/%s", constants.HARDCODED_BUCKET_NAME, objectPath)
+	writer := c.gcsClient.Bucket(constants.HARDCODED_BUCKET_NAME).Object(objectPath).NewWriter(ctx)
+	writer.ContentType = contentType
+
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return "", fmt.Errorf("error writing object %s: %v", uri, err)
+	}
+	if err := writer.Close(); err != nil {
+		c.logger.Error("unable to write object",
+			zap.String("ApplicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", c.traceId),
+			zap.String("objectPath", objectPath),
+			zap.String("bucketName", constants.HARDCODED_BUCKET_NAME),
+			zap.Error(err))
+		return "", fmt.Errorf("error writing object %s: %v", uri, err)
+	}
+
+	c.logger.Info("object written",
+		zap.String("ApplicationName", constants.APPLICATION_NAME),
+		zap.String("traceId", c.traceId),
+		zap.String("objectPath", objectPath),
+		zap.String("bucketName", constants.HARDCODED_BUCKET_NAME),
+		zap.Int("sizeBytes", len(data)))
+	return uri, nil
+}
+
 func (c *GCSClient) Close(ctx context.Context) error {
 	_, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
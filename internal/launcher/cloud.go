@@ -0,0 +1,54 @@
+package launcher
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/batch"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/compute"
+)
+
+// CloudRun launches jobs as Cloud Run job executions.
+type CloudRun struct {
+	compute   *compute.Compute
+	projectId string
+	region    string
+}
+
+// NewCloudRun returns a launcher running jobs in the given project and region.
+func NewCloudRun(compute *compute.Compute, projectId string, region string) *CloudRun {
+	return &CloudRun{compute: compute, projectId: projectId, region: region}
+}
+
+// Launch triggers the named Cloud Run job with the request arguments.
+func (c *CloudRun) Launch(ctx context.Context, req JobRequest) (JobResult, error) {
+	operation, execution, err := c.compute.TriggerFileStreamerJob(ctx, c.projectId, c.region, req.JobName, req.Args, req.Profile, req.Shards)
+	if err != nil {
+		return JobResult{}, err
+	}
+	return JobResult{Operation: operation, Execution: execution}, nil
+}
+
+// CloudBatch launches jobs as Cloud Batch jobs.
+type CloudBatch struct {
+	batch     *batch.Batch
+	projectId string
+	region    string
+}
+
+// NewCloudBatch returns a launcher submitting jobs in the given project and region.
+func NewCloudBatch(batch *batch.Batch, projectId string, region string) *CloudBatch {
+	return &CloudBatch{batch: batch, projectId: projectId, region: region}
+}
+
+// Launch submits a Cloud Batch job sized with the request's resources.
+func (c *CloudBatch) Launch(ctx context.Context, req JobRequest) (JobResult, error) {
+	if req.Batch == nil {
+		return JobResult{}, fmt.Errorf("batch job %q has no resources", req.JobName)
+	}
+	job, err := c.batch.SubmitJob(ctx, c.projectId, c.region, req.JobName, req.Args, *req.Batch, req.Profile)
+	if err != nil {
+		return JobResult{}, err
+	}
+	return JobResult{BatchJob: job}, nil
+}
@@ -0,0 +1,52 @@
+// Package launcher starts the compute job chosen for a file. The JobLauncher
+// interface hides the backend so the processor can run against Cloud Run and
+// Cloud Batch in production, a local process or container on a laptop, or a
+// recording fake in tests.
+package launcher
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+)
+
+// JobRequest describes the job to start for a file.
+// It is serialized into the contract file queue for jobs launched later by
+// the dispatcher.
+type JobRequest struct {
+	FileUrl string                 `json:"fileUrl"`
+	Backend string                 `json:"backend"`           // backend selected by the routing rule
+	JobName string                 `json:"jobName"`           // Cloud Run job, batch job prefix or local command name
+	Args    []string               `json:"args,omitempty"`    // positional arguments rendered by the routing rule
+	Batch   *model.BatchResources  `json:"batch,omitempty"`   // resources for the batch backend
+	Profile *model.ResourceProfile `json:"profile,omitempty"` // optional execution overrides
+	Shards  *model.ShardManifest   `json:"shards,omitempty"`  // optional byte-range shards, one task each
+}
+
+// JobResult identifies the started job. Operation is set when the job can
+// be followed by an execution tracker.
+type JobResult struct {
+	Operation string
+	Execution string
+	BatchJob  string
+}
+
+// JobLauncher starts jobs. Implementations return once the job has been
+// accepted, without waiting for it to finish.
+type JobLauncher interface {
+	Launch(ctx context.Context, req JobRequest) (JobResult, error)
+}
+
+// Backends dispatches each request to the launcher registered for its
+// backend.
+type Backends map[string]JobLauncher
+
+// Launch starts the job with the launcher of the request's backend.
+func (b Backends) Launch(ctx context.Context, req JobRequest) (JobResult, error) {
+	launcher, ok := b[req.Backend]
+	if !ok {
+		return JobResult{}, fmt.Errorf("no launcher configured for backend %q", req.Backend)
+	}
+	return launcher.Launch(ctx, req)
+}
@@ -0,0 +1,210 @@
+package launcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+	"go.uber.org/zap"
+)
+
+// Local launches jobs as local processes, for running the decider end to end
+// against local streamer builds. Each job name maps to a command, such as a
+// binary path or a "docker run" invocation, and the job's positional
+// arguments are appended to it. Local also acts as an execution waiter so
+// the tracker can follow the processes it started.
+type Local struct {
+	logger   *zap.Logger
+	traceId  string
+	commands map[string][]string
+
+	mu         sync.Mutex
+	executions map[string]*localExecution
+}
+
+// localExecution is a started job made of one process per task; done is
+// closed once every task has exited and errs holds their exit errors.
+type localExecution struct {
+	done chan struct{}
+	errs []error
+}
+
+// NewLocal returns a launcher running the given command per job name.
+func NewLocal(logger *zap.Logger, traceId string, commands map[string][]string) *Local {
+	return &Local{
+		logger:     logger,
+		traceId:    traceId,
+		commands:   commands,
+		executions: map[string]*localExecution{},
+	}
+}
+
+// LoadLocal reads the job commands from a JSON file mapping job names to
+// commands, e.g. {"prj-wayne-file-streamer": ["./bin/file-streamer"]}.
+func LoadLocal(logger *zap.Logger, traceId string, path string) (*Local, error) {
+	if path == "" {
+		return nil, fmt.Errorf("%s is required by the local launcher", constants.LOCAL_JOBS_CONFIG)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read local jobs %s: %v", path, err)
+	}
+
+	var commands map[string][]string
+	if err := json.Unmarshal(data, &commands); err != nil {
+		return nil, fmt.Errorf("invalid local jobs format: %v", err)
+	}
+	for jobName, command := range commands {
+		if len(command) == 0 {
+			return nil, fmt.Errorf("local job %q has an empty command", jobName)
+		}
+	}
+	return NewLocal(logger, traceId, commands), nil
+}
+
+// Launch starts the command configured for the job with the request
+// arguments appended and the profile env added to the environment. Like a
+// Cloud Run execution, one process is started per task with
+// CLOUD_RUN_TASK_INDEX and CLOUD_RUN_TASK_COUNT set, and sharded jobs also
+// get the manifest in SHARD_MANIFEST. The processes outlive the request that
+// started them, are killed once the profile timeout passes and write to the
+// decider's stdout and stderr.
+func (l *Local) Launch(ctx context.Context, req JobRequest) (JobResult, error) {
+	command, ok := l.commands[req.JobName]
+	if !ok {
+		return JobResult{}, fmt.Errorf("no local command configured for job %q", req.JobName)
+	}
+
+	var cancel context.CancelFunc
+	jobCtx := context.WithoutCancel(ctx)
+	if req.Profile != nil && req.Profile.TimeoutSeconds > 0 {
+		jobCtx, cancel = context.WithTimeout(jobCtx, time.Duration(req.Profile.TimeoutSeconds)*time.Second)
+	} else {
+		jobCtx, cancel = context.WithCancel(jobCtx)
+	}
+
+	env := os.Environ()
+	taskCount := 1
+	if req.Profile != nil {
+		for name, value := range req.Profile.Env {
+			env = append(env, name+"="+value)
+		}
+		if req.Profile.TaskCount > 0 {
+			taskCount = int(req.Profile.TaskCount)
+		}
+	}
+	if req.Shards != nil {
+		manifest, _ := json.Marshal(req.Shards)
+		env = append(env, constants.SHARD_MANIFEST_ENV+"="+string(manifest))
+		taskCount = len(req.Shards.Shards)
+	}
+
+	// The configured command is shared by concurrent launches and must not be
+	// appended to
+	args := slices.Concat(command[1:], req.Args)
+	cmds := make([]*exec.Cmd, taskCount)
+	for i := range cmds {
+		cmd := exec.CommandContext(jobCtx, command[0], args...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Env = append(slices.Clip(env),
+			fmt.Sprintf("%s=%d", constants.TASK_INDEX_ENV, i),
+			fmt.Sprintf("%s=%d", constants.TASK_COUNT_ENV, taskCount))
+		if err := cmd.Start(); err != nil {
+			// Kill and reap the tasks already started
+			cancel()
+			for _, started := range cmds[:i] {
+				started.Wait()
+			}
+			return JobResult{}, fmt.Errorf("unable to start local job %q: %v", req.JobName, err)
+		}
+		cmds[i] = cmd
+	}
+
+	operation := fmt.Sprintf("local/%s/%d", req.JobName, cmds[0].Process.Pid)
+	execution := &localExecution{done: make(chan struct{}), errs: make([]error, taskCount)}
+	l.mu.Lock()
+	l.executions[operation] = execution
+	l.mu.Unlock()
+
+	l.logger.Info("started local job",
+		zap.String("applicationName", constants.APPLICATION_NAME),
+		zap.String("traceId", l.traceId),
+		zap.String("jobName", req.JobName),
+		zap.String("operation", operation),
+		zap.Int("taskCount", taskCount))
+
+	go func() {
+		defer cancel()
+		var wg sync.WaitGroup
+		for i, cmd := range cmds {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				execution.errs[i] = cmd.Wait()
+			}()
+		}
+		wg.Wait()
+		close(execution.done)
+		l.logger.Info("local job exited",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", l.traceId),
+			zap.String("operation", operation),
+			zap.NamedError("exitError", errors.Join(execution.errs...)))
+	}()
+
+	return JobResult{Operation: operation, Execution: operation}, nil
+}
+
+// WaitExecution waits for every task process started under the operation to
+// exit. Tasks killed by a signal, such as on timeout, count as cancelled.
+func (l *Local) WaitExecution(ctx context.Context, operation string) (model.ExecutionOutcome, error) {
+	outcome := model.ExecutionOutcome{Operation: operation, Execution: operation}
+
+	l.mu.Lock()
+	execution, ok := l.executions[operation]
+	l.mu.Unlock()
+	if !ok {
+		return outcome, fmt.Errorf("unknown local operation %q", operation)
+	}
+
+	select {
+	case <-execution.done:
+	case <-ctx.Done():
+		return outcome, ctx.Err()
+	}
+
+	outcome.TaskCount = int32(len(execution.errs))
+	for _, err := range execution.errs {
+		var exitErr *exec.ExitError
+		switch {
+		case err == nil:
+			outcome.SucceededCount++
+		case errors.As(err, &exitErr) && !exitErr.Exited():
+			outcome.CancelledCount++
+		default:
+			outcome.FailedCount++
+		}
+	}
+	if err := errors.Join(execution.errs...); err != nil {
+		outcome.Message = err.Error()
+	}
+
+	switch {
+	case outcome.FailedCount > 0:
+		outcome.State = constants.EXECUTION_FAILED
+	case outcome.CancelledCount > 0:
+		outcome.State = constants.EXECUTION_CANCELLED
+	default:
+		outcome.State = constants.EXECUTION_SUCCEEDED
+	}
+	return outcome, nil
+}
@@ -0,0 +1,61 @@
+package launcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+	"go.uber.org/zap"
+)
+
+func TestLocalLaunchConcurrentArgs(t *testing.T) {
+	// Commands decoded from JSON keep spare capacity, which concurrent
+	// launches must not write into
+	var commands map[string][]string
+	if err := json.Unmarshal([]byte(`{"job": ["sh", "-c", "test \"$0\" = \"$EXPECTED\""]}`), &commands); err != nil {
+		t.Fatal(err)
+	}
+	local := NewLocal(zap.NewNop(), "trace", commands)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value := fmt.Sprintf("file-%d", i)
+			result, err := local.Launch(ctx, JobRequest{
+				JobName: "job",
+				Args:    []string{value},
+				Profile: &model.ResourceProfile{Env: map[string]string{"EXPECTED": value}},
+			})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			outcome, err := local.WaitExecution(ctx, result.Operation)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if outcome.State != constants.EXECUTION_SUCCEEDED {
+				t.Errorf("launch %d: state %s: %s", i, outcome.State, outcome.Message)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestLocalLaunchUnknownJob(t *testing.T) {
+	local := NewLocal(zap.NewNop(), "trace", map[string][]string{})
+	if _, err := local.Launch(context.Background(), JobRequest{JobName: "missing"}); err == nil {
+		t.Fatal("expected an error for an unknown job")
+	}
+}
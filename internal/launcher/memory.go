@@ -0,0 +1,63 @@
+package launcher
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+)
+
+// Memory is a launcher that records requests instead of starting jobs. It
+// also acts as an execution waiter reporting every execution as succeeded.
+type Memory struct {
+	// Err, when set before use, is returned by every Launch call.
+	Err error
+
+	mu       sync.Mutex
+	requests []JobRequest
+}
+
+// NewMemory returns an empty recording launcher.
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+// Launch records the request and returns fake job names.
+func (m *Memory) Launch(ctx context.Context, req JobRequest) (JobResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests = append(m.requests, req)
+	if m.Err != nil {
+		return JobResult{}, m.Err
+	}
+
+	n := len(m.requests)
+	if req.Backend == constants.BACKEND_BATCH {
+		return JobResult{BatchJob: fmt.Sprintf("memory/jobs/%s-%d", req.JobName, n)}, nil
+	}
+	return JobResult{
+		Operation: fmt.Sprintf("memory/operations/%d", n),
+		Execution: fmt.Sprintf("memory/jobs/%s/executions/%d", req.JobName, n),
+	}, nil
+}
+
+// Requests returns the recorded requests in launch order.
+func (m *Memory) Requests() []JobRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.requests)
+}
+
+// WaitExecution reports the operation as a succeeded single-task execution.
+func (m *Memory) WaitExecution(ctx context.Context, operation string) (model.ExecutionOutcome, error) {
+	return model.ExecutionOutcome{
+		Operation:      operation,
+		State:          constants.EXECUTION_SUCCEEDED,
+		TaskCount:      1,
+		SucceededCount: 1,
+	}, nil
+}
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+)
+
+// JobPayload is the versioned document handed to jobs that take a structured
+// payload instead of positional args. It carries everything the decider
+// learned about the file, so jobs no longer depend on argument order.
+type JobPayload struct {
+	Version           string            `json:"version"`
+	TraceId           string            `json:"traceId"`
+	RequestUUID       string            `json:"requestUUID,omitempty"`
+	Rule              string            `json:"rule"`
+	JobName           string            `json:"jobName"`
+	FileUrl           string            `json:"fileUrl"`
+	FinalUrl          string            `json:"finalUrl,omitempty"`
+	FileName          string            `json:"fileName"`
+	SizeBytes         int64             `json:"sizeBytes"` // -1 when unknown
+	RangeSupported    bool              `json:"rangeSupported"`
+	ContentType       string            `json:"contentType,omitempty"`
+	ContentEncoding   string            `json:"contentEncoding,omitempty"`
+	ETag              string            `json:"etag,omitempty"`
+	LastModified      string            `json:"lastModified,omitempty"`
+	Checksums         map[string]string `json:"checksums,omitempty"`
+	DetectedFormat    string            `json:"detectedFormat,omitempty"`
+	Layers            []string          `json:"layers,omitempty"`
+	PayloadFormat     string            `json:"payloadFormat,omitempty"`
+	UncompressedBytes int64             `json:"uncompressedBytes,omitempty"`
+	Shards            *ShardManifest    `json:"shards,omitempty"`
+}
+
+// NewJobPayload builds the current payload version for a routed file.
+func NewJobPayload(info FileInfo, rule string, jobName string, shards *ShardManifest) JobPayload {
+	sizeBytes := info.SizeBytes
+	if info.SizeUnknown {
+		sizeBytes = -1
+	}
+	// An unreliable gzip estimate is only a lower bound
+	uncompressedBytes := info.UncompressedBytes
+	if info.Gzip != nil && !info.Gzip.Reliable {
+		uncompressedBytes = 0
+	}
+	return JobPayload{
+		Version:           constants.JOB_PAYLOAD_VERSION,
+		TraceId:           info.TraceId,
+		RequestUUID:       info.RequestUUID,
+		Rule:              rule,
+		JobName:           jobName,
+		FileUrl:           info.FIleUrl,
+		FinalUrl:          info.FinalUrl,
+		FileName:          info.FileName,
+		SizeBytes:         sizeBytes,
+		RangeSupported:    info.RangeSupported,
+		ContentType:       info.ContentType,
+		ContentEncoding:   info.ContentEncoding,
+		ETag:              info.ETag,
+		LastModified:      info.LastModified,
+		Checksums:         info.Checksums,
+		DetectedFormat:    info.DetectedFormat,
+		Layers:            info.Layers,
+		PayloadFormat:     info.PayloadFormat,
+		UncompressedBytes: uncompressedBytes,
+		Shards:            shards,
+	}
+}
+
+// Validate checks the payload is complete and consistent before it is sent.
+func (p JobPayload) Validate() error {
+	var errs []error
+	if p.Version != constants.JOB_PAYLOAD_VERSION {
+		errs = append(errs, fmt.Errorf("unsupported payload version %q", p.Version))
+	}
+	if p.TraceId == "" {
+		errs = append(errs, errors.New("traceId is required"))
+	}
+	if p.JobName == "" {
+		errs = append(errs, errors.New("jobName is required"))
+	}
+	if p.FileName == "" {
+		errs = append(errs, errors.New("fileName is required"))
+	}
+	if parsedUrl, err := url.Parse(p.FileUrl); err != nil || !parsedUrl.IsAbs() || parsedUrl.Host == "" {
+		errs = append(errs, fmt.Errorf("fileUrl %q is not an absolute URL", p.FileUrl))
+	}
+	if p.SizeBytes < -1 {
+		errs = append(errs, fmt.Errorf("invalid sizeBytes %d", p.SizeBytes))
+	}
+	if p.UncompressedBytes < 0 {
+		errs = append(errs, fmt.Errorf("invalid uncompressedBytes %d", p.UncompressedBytes))
+	}
+	if p.Shards != nil {
+		next := int64(0)
+		for i, shard := range p.Shards.Shards {
+			if shard.Index != i || shard.Start != next || shard.End < shard.Start {
+				errs = append(errs, fmt.Errorf("shard %d is not contiguous", i))
+				break
+			}
+			next = shard.End + 1
+		}
+		if next != p.SizeBytes {
+			errs = append(errs, fmt.Errorf("shards cover %d of %d bytes", next, p.SizeBytes))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid job payload: %w", errors.Join(errs...))
+	}
+	return nil
+}
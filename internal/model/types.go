@@ -3,17 +3,63 @@ package model
 import "time"
 
 type FileInfo struct {
-	TraceId        string  `json:"traceid"`
-	RequestUUID    string  `json:"requestUUID"`
-	FIleUrl        string  `json:"fileUrl"`
-	FileName       string  `json:"fileName"`
-	RangeSupported bool    `json:"rangeSupported"`
-	FileExtension  string  `json:"fileExtenstion,omitempty"`
-	FileSize       string  `json:"fileSize,omitempty"`
-	FileSizeFloat  float64 `json:"-"`
-	FileSizeBytes  string  `json:"-"`
-	ContentType    string  `json:"contentType,omitempty"`
-	Error          string  `json:"error,omitempty"`
+	TraceId             string            `json:"traceid"`
+	RequestUUID         string            `json:"requestUUID"`
+	FIleUrl             string            `json:"fileUrl"`
+	FileName            string            `json:"fileName"`
+	RangeSupported      bool              `json:"rangeSupported"`
+	FileExtension       string            `json:"fileExtenstion,omitempty"`
+	FileSize            string            `json:"fileSize,omitempty"`
+	FileSizeFloat       float64           `json:"-"`
+	SizeMethod          string            `json:"sizeMethod,omitempty"`
+	SizeUnknown         bool              `json:"sizeUnknown,omitempty"`
+	SizeTier            string            `json:"sizeTier,omitempty"`
+	FileSizeBytes       string            `json:"-"`
+	SizeBytes           int64             `json:"-"`
+	Host                string            `json:"host,omitempty"`
+	StatusCode          int               `json:"statusCode,omitempty"`
+	FinalUrl            string            `json:"finalUrl,omitempty"`
+	ETag                string            `json:"etag,omitempty"`
+	LastModified        string            `json:"lastModified,omitempty"`
+	ContentEncoding     string            `json:"contentEncoding,omitempty"`
+	DispositionFileName string            `json:"dispositionFileName,omitempty"`
+	Checksums           map[string]string `json:"checksums,omitempty"` // e.g. "md5", "crc32c" from x-goog-hash or Content-MD5
+	ContentType         string            `json:"contentType,omitempty"`
+	DetectedFormat      string            `json:"detectedFormat,omitempty"`
+	DetectionMethod     string            `json:"detectionMethod,omitempty"`
+	Layers              []string          `json:"layers,omitempty"`
+	PayloadFormat       string            `json:"payloadFormat,omitempty"`
+	Zip                 *ZipInfo          `json:"zip,omitempty"`
+	Gzip                *GzipInfo         `json:"gzip,omitempty"`
+	UncompressedBytes   int64             `json:"uncompressedBytes,omitempty"`
+	NotAttempted        bool              `json:"notAttempted,omitempty"`
+	Status              string            `json:"status,omitempty"`
+	Decision            *Decision         `json:"decision,omitempty"`
+	Error               string            `json:"error,omitempty"`
+}
+
+// ZipInfo summarises a ZIP archive's central directory, read remotely with
+// range requests.
+type ZipInfo struct {
+	EntryCount             int64    `json:"entryCount"`
+	EntryNames             []string `json:"entryNames,omitempty"`
+	EntryNamesTruncated    bool     `json:"entryNamesTruncated,omitempty"`
+	TotalCompressedBytes   int64    `json:"totalCompressedBytes"`
+	TotalUncompressedBytes int64    `json:"totalUncompressedBytes"`
+	CompressionMethods     []string `json:"compressionMethods,omitempty"`
+	Zip64                  bool     `json:"zip64"`
+	Encrypted              bool     `json:"encrypted"`
+}
+
+// GzipInfo holds the gzip trailer of the last member, read remotely with a
+// range request. ISIZE is the uncompressed size mod 2^32.
+type GzipInfo struct {
+	CRC32                      uint32 `json:"crc32"`
+	ISize                      uint32 `json:"isize"`
+	EstimatedUncompressedBytes int64  `json:"estimatedUncompressedBytes"`
+	MultiMember                bool   `json:"multiMember"`
+	Reliable                   bool   `json:"reliable"`
+	UnreliableReason           string `json:"unreliableReason,omitempty"`
 }
 
 type Arguments struct {
@@ -27,15 +73,145 @@ type Arguments struct {
 }
 
 type AuditEvent struct {
-	TraceID      string    `bigquery:"traceid"`
-	ContractId   string    `json:"contractId"`
-	Event        string    `bigquery:"event"`
-	Status       string    `bigquery:"status"`
-	Timestamp    time.Time `bigquery:"createdTimestamp"`
-	FunctionName string    `bigquery:"functionName"`
-	Environment  string    `bigquery:"environment"`
-	Message      string    `bigquery:"message"`
-	FileUrl      string    `bigquery:"fileUrl"`
+	TraceID      string    `bigquery:"traceid" json:"traceId"`
+	ContractId   string    `bigquery:"contractId" json:"contractId"`
+	Event        string    `bigquery:"event" json:"event"`
+	Status       string    `bigquery:"status" json:"status"`
+	Timestamp    time.Time `bigquery:"createdTimestamp" json:"timestamp"`
+	FunctionName string    `bigquery:"functionName" json:"functionName"`
+	Environment  string    `bigquery:"environment" json:"environment,omitempty"`
+	Message      string    `bigquery:"message" json:"message,omitempty"`
+	FileUrl      string    `bigquery:"fileUrl" json:"fileUrl,omitempty"`
+}
+
+// Decision records which routing rule and job, if any, were chosen for a file,
+// the backend and arguments used and the resulting Cloud Run operation and
+// execution or Cloud Batch job, or the reason nothing was triggered.
+type Decision struct {
+	Rule        string          `json:"rule,omitempty"`
+	JobName     string          `json:"jobName,omitempty"`
+	Backend     string          `json:"backend,omitempty"`
+	Profile     string          `json:"profile,omitempty"`
+	Shards      int             `json:"shards,omitempty"`
+	Payload     string          `json:"payload,omitempty"`
+	PayloadPath string          `json:"payloadPath,omitempty"`
+	Args        []string        `json:"args,omitempty"`
+	Batch       *BatchResources `json:"batch,omitempty"`
+	Operation   string          `json:"operation,omitempty"`
+	Execution   string          `json:"execution,omitempty"`
+	BatchJob    string          `json:"batchJob,omitempty"`
+	ContractId  string          `json:"contractId,omitempty"`
+	Queued      bool            `json:"queued,omitempty"`
+	DryRun      bool            `json:"dryRun,omitempty"`
+	Reason      string          `json:"reason,omitempty"`
+}
+
+// ResourceProfile holds the execution overrides a routing rule applies to its
+// job: container environment, task timeout and task count. Parallelism, CPU
+// and memory can only be overridden per execution on Cloud Batch.
+type ResourceProfile struct {
+	Env            map[string]string `json:"env,omitempty"`
+	TimeoutSeconds int64             `json:"timeoutSeconds,omitempty"`
+	TaskCount      int32             `json:"taskCount,omitempty"`
+	Parallelism    int32             `json:"parallelism,omitempty"` // Cloud Batch only
+	CpuMilli       int64             `json:"cpuMilli,omitempty"`    // Cloud Batch only
+	MemoryMib      int64             `json:"memoryMib,omitempty"`   // Cloud Batch only
+}
+
+// ShardManifest splits a range-capable file into byte ranges processed by
+// parallel tasks. Task i handles Shards[i], reading its index from
+// CLOUD_RUN_TASK_INDEX and the manifest from the SHARD_MANIFEST env var.
+type ShardManifest struct {
+	FileUrl        string      `json:"fileUrl"`
+	SizeBytes      int64       `json:"sizeBytes"`
+	ShardSizeBytes int64       `json:"shardSizeBytes"`
+	Shards         []ByteRange `json:"shards"`
+}
+
+// ByteRange is an inclusive range of bytes, as in an HTTP Range header.
+type ByteRange struct {
+	Index int   `json:"index"`
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+}
+
+// BatchResources is the machine, disk and runtime a Cloud Batch job was
+// submitted with, derived from the file metadata.
+type BatchResources struct {
+	ImageUri          string `json:"imageUri"`
+	MachineType       string `json:"machineType"`
+	DiskSizeGB        int64  `json:"diskSizeGB"`
+	MaxRunTimeMinutes int64  `json:"maxRunTimeMinutes"`
+}
+
+// ExecutionOutcome is the terminal state of a triggered Cloud Run execution
+// with its task counts, as written to the audit table by the tracker.
+type ExecutionOutcome struct {
+	Operation      string `json:"operation"`
+	Execution      string `json:"execution,omitempty"`
+	State          string `json:"state"`
+	TaskCount      int32  `json:"taskCount"`
+	SucceededCount int32  `json:"succeededCount"`
+	FailedCount    int32  `json:"failedCount"`
+	CancelledCount int32  `json:"cancelledCount"`
+	RetriedCount   int32  `json:"retriedCount"`
+	Message        string `json:"message,omitempty"`
+}
+
+// AnalyzeResponse is the body returned by the handler: a per-file result in
+// input order plus a summary of the statuses.
+type AnalyzeResponse struct {
+	TraceId string        `json:"traceId"`
+	DryRun  bool          `json:"dryRun,omitempty"`
+	Summary ResultSummary `json:"summary"`
+	Results []FileInfo    `json:"results"`
+}
+
+// ResultSummary counts the file results of a request by status.
+type ResultSummary struct {
+	Total     int            `json:"total"`
+	Succeeded int            `json:"succeeded"`
+	Failed    int            `json:"failed"`
+	ByStatus  map[string]int `json:"byStatus"`
+}
+
+// RequestState is the progress of an asynchronous request as kept by the
+// state store and returned by the status endpoint. Files that have not
+// finished yet are reported with the pending status.
+type RequestState struct {
+	TraceId     string         `json:"traceId"`
+	RequestUUID string         `json:"requestUUID,omitempty"`
+	Status      string         `json:"status"`
+	DryRun      bool           `json:"dryRun,omitempty"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
+	CompletedAt *time.Time     `json:"completedAt,omitempty"`
+	Summary     *ResultSummary `json:"summary,omitempty"`
+	Results     []FileInfo     `json:"results"`
+}
+
+// AcceptedResponse is returned with 202 when a request is processed
+// asynchronously.
+type AcceptedResponse struct {
+	TraceId   string `json:"traceId"`
+	Status    string `json:"status"`
+	StatusUrl string `json:"statusUrl"`
+}
+
+// AuditStats counts the events handled by the buffered audit writer since
+// it started.
+type AuditStats struct {
+	Enqueued int64 `json:"enqueued"`
+	Written  int64 `json:"written"`
+	Dropped  int64 `json:"dropped"` // rejected because the buffer was full or the writer closed
+	Failed   int64 `json:"failed"`  // rejected by BigQuery
+}
+
+// HealthResponse is returned by the health endpoint, with the audit writer
+// counters once the writer has started.
+type HealthResponse struct {
+	Status string      `json:"status"`
+	Audit  *AuditStats `json:"audit,omitempty"`
 }
 
 type RequestBody struct {
@@ -51,4 +227,29 @@ type ContractFileEvent struct {
 	FunctionName string    `bigquery:"functionName"` // can be null
 	Arguments    string    `bigquery:"arguments"`    // nested struct
 	Environment  string    `bigquery:"environment"`  // can be null
+	JobName      string    `bigquery:"jobName"`      // job the dispatcher launches
+	Priority     int64     `bigquery:"priority"`     // higher is dispatched first
+	JobRequest   string    `bigquery:"jobRequest"`   // serialized launcher.JobRequest
+	Operation    string    `bigquery:"operation"`    // set once the job is launched
+	DispatchedAt time.Time `bigquery:"dispatchedAt"` // set when the entry is claimed
+}
+
+// DispatchResult is the outcome of launching one queued contract file.
+type DispatchResult struct {
+	TraceId    string `json:"traceId"` // trace of the request that queued the file
+	ContractId string `json:"contractId"`
+	JobName    string `json:"jobName"`
+	Status     string `json:"status"`
+	Operation  string `json:"operation,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// DispatchResponse is returned by the dispatcher: the executions it found
+// finished, the executions running per job once it was done and the files
+// it launched.
+type DispatchResponse struct {
+	TraceId    string           `json:"traceId"`
+	Reconciled int              `json:"reconciled"`
+	Running    map[string]int   `json:"running"`
+	Results    []DispatchResult `json:"results"`
 }
@@ -4,182 +4,479 @@ package processor
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
 	"net/url"
 	"path"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/bigquery"
-	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/compute"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/audit"
 	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/gcs"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/launcher"
 	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/routing"
 	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
 	"go.uber.org/zap"
 )
 
+// ExecutionTracker follows a triggered execution to its terminal state and
+// calls onDone, when set, with the outcome.
+type ExecutionTracker interface {
+	Track(ctx context.Context, fileUrl string, operation string, onDone func(outcome model.ExecutionOutcome))
+}
+
+// Config holds the processor tunables and optional hooks.
+type Config struct {
+	Concurrency        int  // maximum number of files processed at once
+	PerHostConcurrency int  // maximum number of files processed at once per host, 0 means no limit
+	DryRun             bool // run the full decision path without triggering jobs
+
+	// OnResult, when set, is called from the worker goroutines as soon as a
+	// file's result is known so callers can report progress.
+	OnResult func(index int, result model.FileInfo)
+
+	// Tracker, when set, follows every triggered execution to its outcome.
+	Tracker ExecutionTracker
+
+	// RunningWindow is how long a dispatched queue entry counts against its
+	// job's concurrency limit, normally the execution tracking timeout.
+	RunningWindow time.Duration
+
+	// Dispatch, when set, is called once a queued file's execution has
+	// finished, to launch the queued files waiting for its slot.
+	Dispatch func(ctx context.Context)
+}
+
+// ContractQueue records files in the contract file queue and follows their
+// status. It is implemented by *bigquery.Client.
+type ContractQueue interface {
+	ContractFileQueue(ctx context.Context, event model.ContractFileEvent) error
+	ClaimContractFile(ctx context.Context, traceId string, contractId string, jobName string, maxRunning int, window time.Duration) (bool, error)
+	SetContractFileOperation(ctx context.Context, traceId string, contractId string, operation string) error
+	UpdateContractFileStatus(ctx context.Context, traceId string, contractId string, status string) error
+}
+
 // Processor coordinates the logic for analyzing files and deciding compute actions.
 type Processor struct {
-	traceId       string
-	logger        *zap.Logger
-	fileUrl       []string
-	client        *bigquery.Client
-	gcs           *gcs.GCSClient
-	compute       *compute.Compute
-	projectId     string
-	projectRegion string
-	jobName       string
+	config   Config
+	traceId  string
+	logger   *zap.Logger
+	fileUrl  []string
+	audit    audit.AuditSink
+	queue    ContractQueue
+	gcs      *gcs.GCSClient
+	launcher launcher.JobLauncher
+	router   *routing.Router
+	jobName  string
 }
 
 // NewProcessor creates and returns a new instance of Processor with all required dependencies.
-func NewProcessor(traceId string, fileUrl []string, logger *zap.Logger, client *bigquery.Client, compute *compute.Compute, projectId string, region string, jobName string, gcs *gcs.GCSClient) *Processor {
+// Jobs are started through the launcher, which selects the compute backend, and
+// audit events are written to the audit sink.
+func NewProcessor(traceId string, fileUrl []string, logger *zap.Logger, audit audit.AuditSink, queue ContractQueue, launcher launcher.JobLauncher, router *routing.Router, jobName string, gcs *gcs.GCSClient, config Config) *Processor {
 	return &Processor{
-		traceId:       traceId,
-		logger:        logger,
-		fileUrl:       fileUrl,
-		client:        client,
-		compute:       compute,
-		projectId:     projectId,
-		projectRegion: region,
-		jobName:       jobName,
-		gcs:           gcs,
+		config:   config,
+		traceId:  traceId,
+		logger:   logger,
+		fileUrl:  fileUrl,
+		audit:    audit,
+		queue:    queue,
+		launcher: launcher,
+		router:   router,
+		jobName:  jobName,
+		gcs:      gcs,
 	}
 }
 
-// AnalyzeFileUrls iterates over the provided file URLs, analyzes each one,
-// and determines whether to trigger a compute job.
+// AnalyzeFileUrls analyzes the provided file URLs with a bounded worker pool,
+// applies the size policy and determines whether to trigger a compute job for
+// each. At most Config.Concurrency files are processed at once and at most
+// Config.PerHostConcurrency per host. Results keep the input order; when the
+// context is cancelled, outstanding probes stop and files that were never
+// started are reported as not attempted.
 func (p *Processor) AnalyzeFileUrls(ctx context.Context, fileUrls []string, requestUUID string) []model.FileInfo {
-	var requests []model.FileInfo
-	for _, fileUrl := range fileUrls {
-		fileInfo := p.analyzeFile(ctx, fileUrl, requestUUID)
-		isProcessed, err := p.gcs.CheckAlreadyProcessed(fileInfo, ctx, requestUUID)
-		if err != nil {
-			p.client.LogAuditData(ctx, model.AuditEvent{
-				TraceID:      p.traceId,
-				ContractId:   p.traceId,
-				Event:        constants.FAILED_TO_CHECK_IF_FILE_EXISTS,
-				FileUrl:      fileUrl,
-				Status:       constants.FAILED,
-				Timestamp:    time.Now(),
-				FunctionName: constants.APPLICATION_NAME,
-			})
-			fileInfo.Error = err.Error()
-		} else if !isProcessed {
-			err := p.decideCompute(ctx, fileInfo)
-			if err != nil {
-				p.client.LogAuditData(ctx, model.AuditEvent{
-					TraceID:      p.traceId,
-					ContractId:   p.traceId,
-					Event:        constants.FAILED_TRIGGER_CLOUD_RUN_JOB,
-					FileUrl:      fileUrl,
-					Status:       constants.FAILED,
-					Timestamp:    time.Now(),
-					FunctionName: constants.APPLICATION_NAME,
-				})
-				fileInfo.Error = err.Error()
+	results := make([]model.FileInfo, len(fileUrls))
+	attempted := make([]bool, len(fileUrls))
+	hosts := newHostLimiter(p.config.PerHostConcurrency)
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(max(p.config.Concurrency, 1), max(len(fileUrls), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				host := hostOf(fileUrls[i])
+				if !hosts.acquire(ctx, host) {
+					continue
+				}
+				attempted[i] = true
+				results[i] = p.processFile(ctx, fileUrls[i], requestUUID)
+				hosts.release(host)
+				p.reportResult(i, results[i])
 			}
+		}()
+	}
 
+feed:
+	for i := range fileUrls {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
 		}
-		requests = append(requests, fileInfo)
 	}
+	close(indexes)
+	wg.Wait()
 
-	return requests
+	for i, fileUrl := range fileUrls {
+		if !attempted[i] {
+			results[i] = p.notAttempted(ctx, fileUrl, requestUUID)
+			p.reportResult(i, results[i])
+		}
+	}
+	return results
 }
 
-// decideCompute determines the compute action to take based on file extension (e.g. .gz or .zip).
-// It logs appropriate audit events and triggers cloud run jobs or queues messages.
-func (p *Processor) decideCompute(ctx context.Context, request model.FileInfo) error {
-	ext := request.FileExtension
-	switch ext {
-	case constants.JSON:
-		p.logger.Info("File extension is JSON",
-			zap.String("applicationName", constants.APPLICATION_NAME),
-			zap.String("traceId", p.traceId),
-			zap.String("fileSize", request.FileSize))
+// reportResult hands a finished file result to the OnResult callback.
+func (p *Processor) reportResult(index int, result model.FileInfo) {
+	if p.config.OnResult != nil {
+		p.config.OnResult(index, result)
+	}
+}
+
+// processFile analyzes a single file URL and triggers its compute job unless
+// the file was rejected or already processed. The outcome is recorded in the
+// file's Status so callers can retry only what failed.
+func (p *Processor) processFile(ctx context.Context, fileUrl string, requestUUID string) model.FileInfo {
+	fileInfo := p.analyzeFile(ctx, fileUrl, requestUUID)
+	if fileInfo.Error != "" {
+		fileInfo.Status = constants.FILE_STATUS_PROBE_FAILED
+		return fileInfo
+	}
+
+	if !p.applySizePolicy(ctx, &fileInfo) {
+		fileInfo.Status = constants.FILE_STATUS_REJECTED_BY_POLICY
+		return fileInfo
+	}
 
-		p.client.LogAuditData(ctx, model.AuditEvent{
+	isProcessed, err := p.gcs.CheckAlreadyProcessed(fileInfo, ctx, requestUUID)
+	if err != nil {
+		p.audit.LogAuditData(ctx, model.AuditEvent{
 			TraceID:      p.traceId,
 			ContractId:   p.traceId,
-			Event:        constants.TRIGGER_CLOUD_RUN_JOB,
-			Status:       constants.IN_PROGRESS,
+			Event:        constants.FAILED_TO_CHECK_IF_FILE_EXISTS,
+			FileUrl:      fileUrl,
+			Status:       constants.FAILED,
 			Timestamp:    time.Now(),
-			FileUrl:      request.FIleUrl,
 			FunctionName: constants.APPLICATION_NAME,
 		})
+		fileInfo.Error = err.Error()
+		fileInfo.Status = constants.FILE_STATUS_CHECK_FAILED
+		return fileInfo
+	}
+	if isProcessed {
+		fileInfo.Status = constants.FILE_STATUS_ALREADY_PROCESSED
+		fileInfo.Decision = &model.Decision{Reason: "file already processed for this request"}
+		return fileInfo
+	}
 
-		args := []string{request.TraceId, request.FIleUrl, request.FileSizeBytes, request.RequestUUID}
-		err := p.compute.TriggerFileStreamerJob(ctx, p.projectId, p.projectRegion, constants.CLOUD_RUN_JOB_NAME, args)
-		if err != nil {
-			p.logger.Error("error triggering cloud run job",
-				zap.String("applicationName", constants.APPLICATION_NAME),
-				zap.String("traceId", p.traceId),
-				zap.String("fileSize", request.FileSize),
-				zap.Error(err))
-			return err
+	triggered, err := p.decideCompute(ctx, &fileInfo)
+	if err != nil {
+		event := constants.FAILED_TRIGGER_CLOUD_RUN_JOB
+		if fileInfo.Decision.Backend == constants.BACKEND_BATCH {
+			event = constants.FAILED_TRIGGER_CLOUD_BATCH_JOB
 		}
-		return nil
+		p.audit.LogAuditData(ctx, model.AuditEvent{
+			TraceID:      p.traceId,
+			ContractId:   p.traceId,
+			Event:        event,
+			FileUrl:      fileUrl,
+			Status:       constants.FAILED,
+			Timestamp:    time.Now(),
+			FunctionName: constants.APPLICATION_NAME,
+			Message:      decisionMessage(fileInfo.Decision),
+		})
+		fileInfo.Error = err.Error()
+		fileInfo.Status = constants.FILE_STATUS_TRIGGER_FAILED
+		return fileInfo
+	}
+	if triggered && p.config.DryRun {
+		fileInfo.Status = constants.FILE_STATUS_PLANNED
+	} else if triggered && fileInfo.Decision.Queued {
+		fileInfo.Status = constants.FILE_STATUS_QUEUED
+	} else if triggered {
+		fileInfo.Status = constants.FILE_STATUS_TRIGGERED
+	} else {
+		fileInfo.Status = constants.FILE_STATUS_NO_MATCH
+	}
+	return fileInfo
+}
+
+// notAttempted builds the result for a file that was never started because
+// the request context was cancelled.
+func (p *Processor) notAttempted(ctx context.Context, fileUrl string, requestUUID string) model.FileInfo {
+	message := fmt.Sprintf("file was not attempted: %v", context.Cause(ctx))
+	p.logger.Warn("file not attempted",
+		zap.String("applicationName", constants.APPLICATION_NAME),
+		zap.String("traceId", p.traceId),
+		zap.String("fileUrl", fileUrl),
+		zap.Error(context.Cause(ctx)))
 
-	case constants.GZ:
-		p.logger.Info("File extension is GZ",
+	p.audit.LogAuditData(context.WithoutCancel(ctx), model.AuditEvent{
+		TraceID:      p.traceId,
+		ContractId:   p.traceId,
+		Event:        constants.FILE_NOT_ATTEMPTED,
+		FileUrl:      fileUrl,
+		Status:       constants.FAILED,
+		Timestamp:    time.Now(),
+		FunctionName: constants.APPLICATION_NAME,
+		Message:      message,
+	})
+
+	return model.FileInfo{
+		TraceId:      p.traceId,
+		RequestUUID:  requestUUID,
+		FIleUrl:      fileUrl,
+		NotAttempted: true,
+		Status:       constants.FILE_STATUS_NOT_ATTEMPTED,
+		Error:        message,
+	}
+}
+
+// applySizePolicy assigns the file its size tier and rejects it when it exceeds
+// the configured size limits. It returns false when the file was rejected.
+func (p *Processor) applySizePolicy(ctx context.Context, fileInfo *model.FileInfo) bool {
+	fileInfo.SizeTier = p.router.SizeTier(*fileInfo)
+	message, violated := p.router.CheckSize(*fileInfo)
+	if !violated {
+		return true
+	}
+
+	p.logger.Info("file rejected by size policy",
+		zap.String("applicationName", constants.APPLICATION_NAME),
+		zap.String("traceId", p.traceId),
+		zap.String("fileUrl", fileInfo.FIleUrl),
+		zap.String("fileSize", fileInfo.FileSize),
+		zap.String("reason", message))
+
+	fileInfo.Decision = &model.Decision{Reason: message}
+
+	p.audit.LogAuditData(ctx, model.AuditEvent{
+		TraceID:      p.traceId,
+		ContractId:   p.traceId,
+		Event:        constants.FILE_SIZE_LIMIT_EXCEEDED,
+		FileUrl:      fileInfo.FIleUrl,
+		Status:       constants.FAILED,
+		Timestamp:    time.Now(),
+		FunctionName: constants.APPLICATION_NAME,
+		Message:      message,
+	})
+	fileInfo.Error = message
+	return false
+}
+
+// decideCompute determines the compute action to take by evaluating the routing rules
+// against the file metadata. It logs appropriate audit events, launches the job named
+// by the matched rule on the rule's backend and records the outcome as the file's
+// decision. Files routed by a queueing rule are first recorded in the contract file
+// queue, and their entry follows the job from QUEUED to DONE or FAILED; when the
+// rule defers dispatch the file is left queued for the dispatcher instead. It
+// reports whether a job was triggered, queued, or in dry-run mode would have
+// been; no job is triggered when no rule matches.
+func (p *Processor) decideCompute(ctx context.Context, request *model.FileInfo) (bool, error) {
+	rule, ok := p.router.Match(*request)
+	if !ok {
+		request.Decision = &model.Decision{Reason: "no routing rule matched"}
+		p.logger.Info("no routing rule matched",
 			zap.String("applicationName", constants.APPLICATION_NAME),
 			zap.String("traceId", p.traceId),
-			zap.String("fileSize", request.FileSize))
+			zap.String("extension", request.FileExtension),
+			zap.String("contentType", request.ContentType))
 
-		p.client.LogAuditData(ctx, model.AuditEvent{
+		p.audit.LogAuditData(ctx, model.AuditEvent{
 			TraceID:      p.traceId,
 			ContractId:   p.traceId,
-			Event:        constants.TRIGGER_CLOUD_RUN_JOB,
-			Status:       constants.IN_PROGRESS,
+			Event:        constants.NO_ROUTING_RULE_MATCHED,
+			Status:       constants.COMPLETED,
 			Timestamp:    time.Now(),
 			FileUrl:      request.FIleUrl,
 			FunctionName: constants.APPLICATION_NAME,
+			Message:      decisionMessage(request.Decision),
 		})
+		return false, nil
+	}
 
-		args := []string{request.TraceId, request.FIleUrl, request.FileSizeBytes, request.RequestUUID}
-		err := p.compute.TriggerFileStreamerJob(ctx, p.projectId, p.projectRegion, constants.GZ_JOB_NAME, args)
-		if err != nil {
-			p.logger.Error("error triggering cloud run job",
-				zap.String("applicationName", constants.APPLICATION_NAME),
-				zap.String("traceId", p.traceId),
-				zap.String("fileSize", request.FileSize),
-				zap.Error(err))
-			return err
-		}
-		return nil
-	case constants.ZIP:
-		p.logger.Info("File extension is ZIP",
+	decision := &model.Decision{Rule: rule.Name, JobName: rule.Job, Backend: rule.Backend, Profile: rule.Profile}
+	request.Decision = decision
+
+	p.logger.Info("routing rule matched",
+		zap.String("applicationName", constants.APPLICATION_NAME),
+		zap.String("traceId", p.traceId),
+		zap.String("rule", rule.Name),
+		zap.String("jobName", rule.Job),
+		zap.String("fileSize", request.FileSize))
+
+	args, err := rule.RenderArgs(*request)
+	if err != nil {
+		decision.Reason = err.Error()
+		p.logger.Error("error rendering job arguments",
 			zap.String("applicationName", constants.APPLICATION_NAME),
 			zap.String("traceId", p.traceId),
-			zap.String("fileSize", request.FileSize))
+			zap.String("rule", rule.Name),
+			zap.Error(err))
+		return false, err
+	}
+	shards := rule.ShardManifest(*request)
+	if shards != nil {
+		decision.Shards = len(shards.Shards)
+	}
 
-		p.client.LogAuditData(ctx, model.AuditEvent{
+	payloadArgs, payloadEnv, err := p.buildPayload(ctx, request, rule, shards, decision)
+	if err != nil {
+		decision.Reason = err.Error()
+		p.logger.Error("error building job payload",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", p.traceId),
+			zap.String("rule", rule.Name),
+			zap.Error(err))
+		return false, err
+	}
+	args = append(args, payloadArgs...)
+	decision.Args = args
+	if rule.Backend == constants.BACKEND_BATCH {
+		resources := rule.BatchResources(*request)
+		decision.Batch = &resources
+	}
+
+	if p.config.DryRun {
+		decision.DryRun = true
+		p.audit.LogAuditData(ctx, model.AuditEvent{
 			TraceID:      p.traceId,
 			ContractId:   p.traceId,
-			Event:        constants.TRIGGER_CLOUD_RUN_JOB,
-			Status:       constants.IN_PROGRESS,
+			Event:        constants.JOB_PLANNED,
+			Status:       constants.COMPLETED,
 			Timestamp:    time.Now(),
 			FileUrl:      request.FIleUrl,
 			FunctionName: constants.APPLICATION_NAME,
+			Message:      decisionMessage(decision),
 		})
+		return true, nil
+	}
+
+	jobRequest := launcher.JobRequest{
+		FileUrl: request.FIleUrl,
+		Backend: rule.Backend,
+		JobName: rule.Job,
+		Args:    args,
+		Batch:   decision.Batch,
+		Profile: withEnv(rule.ResourceProfile(), expectedSizeEnv(payloadEnv, *request)),
+		Shards:  shards,
+	}
+
+	if rule.Queue {
+		if err := p.enqueueContractFile(ctx, request, decision, rule.Priority, jobRequest); err != nil {
+			decision.Reason = err.Error()
+			p.logger.Error("error queueing contract file",
+				zap.String("applicationName", constants.APPLICATION_NAME),
+				zap.String("traceId", p.traceId),
+				zap.String("rule", rule.Name),
+				zap.Error(err))
+			return false, err
+		}
+		if rule.Dispatch == constants.DISPATCH_DEFERRED {
+			decision.Queued = true
+			return true, nil
+		}
 
-		args := []string{request.TraceId, request.FIleUrl, request.FileName}
-		err := p.compute.TriggerFileStreamerJob(ctx, p.projectId, p.projectRegion, constants.ZIP_DOWNLOADER_JOB_NAME, args)
+		// Claim the entry so a concurrent dispatcher cannot launch it too, and
+		// only while the job is below its concurrency limit
+		claimed, err := p.queue.ClaimContractFile(ctx, p.traceId, decision.ContractId, rule.Job, p.router.MaxRunning(rule.Job), p.config.RunningWindow)
 		if err != nil {
-			p.logger.Error("error triggering cloud run job",
+			decision.Reason = err.Error()
+			p.logger.Error("error claiming contract file",
 				zap.String("applicationName", constants.APPLICATION_NAME),
 				zap.String("traceId", p.traceId),
-				zap.String("fileSize", request.FileSize),
+				zap.String("contractId", decision.ContractId),
 				zap.Error(err))
+			p.failUnclaimedContractFile(ctx, request.FIleUrl, decision.ContractId, err)
+			return false, err
+		}
+		if !claimed {
+			decision.Queued = true
+			decision.Reason = "job at its concurrency limit, contract file left queued for the dispatcher"
+			return true, nil
+		}
+	}
 
-			return err
+	triggerEvent, triggeredEvent := constants.TRIGGER_CLOUD_RUN_JOB, constants.CLOUD_RUN_JOB_TRIGGERED
+	if rule.Backend == constants.BACKEND_BATCH {
+		triggerEvent, triggeredEvent = constants.TRIGGER_CLOUD_BATCH_JOB, constants.CLOUD_BATCH_JOB_SUBMITTED
+	}
+
+	p.audit.LogAuditData(ctx, model.AuditEvent{
+		TraceID:      p.traceId,
+		ContractId:   p.traceId,
+		Event:        triggerEvent,
+		Status:       constants.IN_PROGRESS,
+		Timestamp:    time.Now(),
+		FileUrl:      request.FIleUrl,
+		FunctionName: constants.APPLICATION_NAME,
+		Message:      decisionMessage(decision),
+	})
+
+	result, err := p.launcher.Launch(ctx, jobRequest)
+	if err != nil {
+		decision.Reason = err.Error()
+		p.logger.Error("error launching job",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", p.traceId),
+			zap.String("backend", rule.Backend),
+			zap.String("fileSize", request.FileSize),
+			zap.Error(err))
+		if decision.ContractId != "" {
+			p.updateContractFile(ctx, request.FIleUrl, decision.ContractId, constants.QUEUE_STATUS_FAILED)
 		}
-		return nil
+		return false, err
+	}
+	decision.Operation = result.Operation
+	decision.Execution = result.Execution
+	decision.BatchJob = result.BatchJob
 
-	default:
-		return nil
+	p.audit.LogAuditData(ctx, model.AuditEvent{
+		TraceID:      p.traceId,
+		ContractId:   p.traceId,
+		Event:        triggeredEvent,
+		Status:       constants.COMPLETED,
+		Timestamp:    time.Now(),
+		FileUrl:      request.FIleUrl,
+		FunctionName: constants.APPLICATION_NAME,
+		Message:      decisionMessage(decision),
+	})
+
+	var onDone func(outcome model.ExecutionOutcome)
+	if decision.ContractId != "" {
+		p.recordContractFileOperation(ctx, request.FIleUrl, decision.ContractId, result.Operation)
+		onDone = p.contractFileDone(ctx, request.FIleUrl, decision.ContractId)
+	}
+
+	if p.config.Tracker != nil && result.Operation != "" {
+		p.config.Tracker.Track(ctx, request.FIleUrl, result.Operation, onDone)
 	}
+	return true, nil
+}
+
+// decisionMessage serializes a decision for the audit event message.
+func decisionMessage(decision *model.Decision) string {
+	message, err := json.Marshal(decision)
+	if err != nil {
+		return decision.Reason
+	}
+	return string(message)
 }
 
 // getFileNameFromURL extracts the file name from a URL path.
@@ -191,11 +488,14 @@ func (p *Processor) getFileNameFromURL(rawURL string) (string, error) {
 	return path.Base(parsedUrl.Path), nil
 }
 
-// analyzeFile performs a HEAD request to gather metadata about the file,
-// such as content length, content type, extension, and range support.
+// analyzeFile probes the file, starting with a HEAD request, to gather metadata
+// such as content length, content type, extension, and range support, and
+// detects the file format, sniffing the content when the metadata is ambiguous.
 func (p *Processor) analyzeFile(ctx context.Context, fileUrl string, requestUUID string) model.FileInfo {
 	var info model.FileInfo
 	info.RequestUUID = requestUUID
+	info.TraceId = p.traceId
+	info.FIleUrl = fileUrl
 
 	parsedUrl, err := url.Parse(fileUrl)
 	if err != nil {
@@ -206,7 +506,7 @@ func (p *Processor) analyzeFile(ctx context.Context, fileUrl string, requestUUID
 		info.Error = fmt.Sprintf("Invalid URL %s: %v", fileUrl, err)
 		return info
 	}
-	p.client.LogAuditData(ctx, model.AuditEvent{
+	p.audit.LogAuditData(ctx, model.AuditEvent{
 		TraceID:      p.traceId,
 		ContractId:   p.traceId,
 		Event:        constants.ANALYZE_FILE_STARTED,
@@ -218,34 +518,24 @@ func (p *Processor) analyzeFile(ctx context.Context, fileUrl string, requestUUID
 
 	info.FileExtension = path.Ext(parsedUrl.Path)
 
-	req, err := http.NewRequestWithContext(ctx, constants.HEAD, fileUrl, nil)
+	probe, err := p.probe(ctx, fileUrl)
 	if err != nil {
-		info.Error = fmt.Sprintf("Failed to create HEAD request URL: %s, error : %v", fileUrl, err)
-		p.logger.Error("unable to create HEAD Request",
-			zap.String("applicationName", constants.APPLICATION_NAME),
-			zap.String("traceId", p.traceId),
-			zap.Error(err))
-		return info
-	}
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		info.Error = fmt.Sprintf("Failed to execute HEAD request URL: %s, error : %v", fileUrl, err)
-		p.logger.Error("unable to create HEAD Request",
+		var statusErr *HTTPStatusError
+		if errors.As(err, &statusErr) {
+			info.StatusCode = statusErr.StatusCode
+		}
+		info.Error = fmt.Sprintf("Failed to probe file URL: %s, error : %v", fileUrl, err)
+		p.logger.Error("unable to probe file",
 			zap.String("applicationName", constants.APPLICATION_NAME),
 			zap.String("traceId", p.traceId),
+			zap.Int("statusCode", info.StatusCode),
 			zap.Error(err))
 		return info
 	}
-
-	defer resp.Body.Close()
-
-	fileSize := resp.Header.Get(constants.CONTENT_LENGTH)
-	acceptRanges := resp.Header.Get(constants.RANGE_SUPPORTED)
-	if acceptRanges == constants.BYTES {
-		info.RangeSupported = true
-	} else {
-		info.RangeSupported = false
+	info.RangeSupported = probe.rangeSupported
+	applyResponseMetadata(&info, probe)
+	if info.FileExtension == "" && info.DispositionFileName != "" {
+		info.FileExtension = path.Ext(info.DispositionFileName)
 	}
 
 	fileName, err := p.getFileNameFromURL(fileUrl)
@@ -260,23 +550,24 @@ func (p *Processor) analyzeFile(ctx context.Context, fileUrl string, requestUUID
 
 	info.FileName = fileName
 
-	fileSizeConverted, err := strconv.ParseInt(fileSize, 10, 64)
-	if err != nil {
-		info.Error = fmt.Sprintf("error parsing content length, fileURL: %s, error: %v", fileUrl, err)
-		p.logger.Error("error parsing content length",
-			zap.String("applicationName", constants.APPLICATION_NAME),
-			zap.String("traceId", p.traceId),
-			zap.Error(err))
-		return info
+	if probe.sizeBytes >= 0 {
+		fileSizeGB := float64(probe.sizeBytes) / constants.FILE_SIZE_BYTES
+		info.FileSizeFloat = fileSizeGB
+		info.FileSizeBytes = strconv.FormatInt(probe.sizeBytes, 10)
+		info.FileSize = fmt.Sprintf("%.2f GB", fileSizeGB)
+		info.SizeBytes = probe.sizeBytes
+		info.SizeMethod = probe.sizeMethod
+	} else {
+		info.SizeUnknown = true
 	}
+	info.ContentType = probe.header.Get(constants.CONTENT_TYPE)
+	info.Host = parsedUrl.Hostname()
 
-	fileSizeGB := float64(fileSizeConverted) / constants.FILE_SIZE_BYTES
-	info.FileSizeFloat = fileSizeGB
-	info.FileSizeBytes = fileSize
-	info.FileSize = fmt.Sprintf("%.2f GB", fileSizeGB)
-	info.ContentType = resp.Header.Get(constants.CONTENT_TYPE)
-	info.TraceId = p.traceId
-	info.FIleUrl = fileUrl
+	parseLayers(&info)
+	p.detectFormat(ctx, &info)
+	applyDetectedLayer(&info)
+	p.inspectZip(ctx, &info)
+	p.inspectGzip(ctx, &info)
 
 	if info.FileExtension == "" && info.ContentType != "" {
 		parts := strings.Split(info.ContentType, "/")
@@ -285,7 +576,7 @@ func (p *Processor) analyzeFile(ctx context.Context, fileUrl string, requestUUID
 		}
 	}
 
-	p.client.LogAuditData(ctx, model.AuditEvent{
+	p.audit.LogAuditData(ctx, model.AuditEvent{
 		TraceID:      p.traceId,
 		ContractId:   p.traceId,
 		Event:        constants.ANALYZE_FILE_COMPLETED,
@@ -299,7 +590,25 @@ func (p *Processor) analyzeFile(ctx context.Context, fileUrl string, requestUUID
 		zap.String("applicationName", constants.APPLICATION_NAME),
 		zap.String("traceId", p.traceId),
 		zap.String("extension", info.FileExtension), zap.String("file size", info.FileSize),
-		zap.String("content type", info.ContentType))
+		zap.String("content type", info.ContentType),
+		zap.String("detected format", info.DetectedFormat),
+		zap.String("detection method", info.DetectionMethod))
 
 	return info
 }
+
+// Summarize counts the results by status. Triggered, planned and skipped files
+// count as succeeded; every other status counts as failed.
+func Summarize(results []model.FileInfo) model.ResultSummary {
+	summary := model.ResultSummary{Total: len(results), ByStatus: map[string]int{}}
+	for _, res := range results {
+		summary.ByStatus[res.Status]++
+		switch res.Status {
+		case constants.FILE_STATUS_TRIGGERED, constants.FILE_STATUS_PLANNED, constants.FILE_STATUS_QUEUED, constants.FILE_STATUS_ALREADY_PROCESSED, constants.FILE_STATUS_NO_MATCH:
+			summary.Succeeded++
+		default:
+			summary.Failed++
+		}
+	}
+	return summary
+}
@@ -0,0 +1,43 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+)
+
+// fetchRange issues a ranged GET for the inclusive byte range [start, end] and
+// returns the bytes read. Servers that ignore the Range header and answer with
+// the full body are tolerated; only the requested number of bytes is read.
+func (p *Processor) fetchRange(ctx context.Context, fileUrl string, start int64, end int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileUrl, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create range request: %v", err)
+	}
+	req.Header.Set(constants.RANGE, fmt.Sprintf("bytes=%d-%d", start, end))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute range request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		if start != 0 {
+			return nil, fmt.Errorf("server ignored range request for offset %d", start)
+		}
+	default:
+		return nil, fmt.Errorf("unexpected status for range request: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, end-start+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read range response: %v", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,153 @@
+package processor
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"strconv"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+	"go.uber.org/zap"
+)
+
+// gzip header and trailer layout, see RFC 1952 section 2.3.
+const (
+	gzipHeaderLen    = 10
+	gzipTrailerLen   = 8
+	gzipExtraFlag    = 0x04
+	gzipMinMemberLen = gzipHeaderLen + gzipTrailerLen
+	gzipISizeModulus = int64(1) << 32
+)
+
+// isGzip reports whether the outermost layer of the file is gzip.
+func isGzip(info model.FileInfo) bool {
+	return len(info.Layers) > 0 && info.Layers[0] == "gzip"
+}
+
+// inspectGzip reads the gzip trailer of the last member to record the CRC32
+// and ISIZE (uncompressed size mod 2^32). The estimate is flagged as unreliable
+// when the file is larger than 4 GiB or made of several members, in which
+// case ISIZE only describes part of the data.
+func (p *Processor) inspectGzip(ctx context.Context, info *model.FileInfo) {
+	if !isGzip(*info) || !info.RangeSupported || info.SizeBytes < gzipMinMemberLen {
+		return
+	}
+
+	gzipInfo, err := p.readGzipTrailer(ctx, info.FIleUrl, info.SizeBytes)
+	if err != nil {
+		p.logger.Warn("unable to read gzip trailer",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", p.traceId),
+			zap.String("fileUrl", info.FIleUrl),
+			zap.Error(err))
+		return
+	}
+	info.Gzip = gzipInfo
+	info.UncompressedBytes = gzipInfo.EstimatedUncompressedBytes
+
+	p.logger.Info("gzip trailer inspected",
+		zap.String("applicationName", constants.APPLICATION_NAME),
+		zap.String("traceId", p.traceId),
+		zap.String("fileUrl", info.FIleUrl),
+		zap.Uint32("isize", gzipInfo.ISize),
+		zap.Int64("estimatedUncompressedBytes", gzipInfo.EstimatedUncompressedBytes),
+		zap.Bool("reliable", gzipInfo.Reliable))
+}
+
+// readGzipTrailer fetches the header and the last 8 bytes of the remote file.
+// When the trailer cannot be trusted the estimate is a lower bound.
+func (p *Processor) readGzipTrailer(ctx context.Context, fileUrl string, size int64) (*model.GzipInfo, error) {
+	header, err := p.fetchRange(ctx, fileUrl, 0, constants.GZIP_HEADER_BYTES-1)
+	if err != nil {
+		return nil, err
+	}
+	if len(header) < gzipHeaderLen || header[0] != 0x1f || header[1] != 0x8b {
+		return nil, fmt.Errorf("invalid gzip header")
+	}
+
+	trailer, err := p.fetchRange(ctx, fileUrl, size-gzipTrailerLen, size-1)
+	if err != nil {
+		return nil, err
+	}
+	if len(trailer) != gzipTrailerLen {
+		return nil, fmt.Errorf("short gzip trailer of %d bytes", len(trailer))
+	}
+
+	isize := binary.LittleEndian.Uint32(trailer[4:])
+	gzipInfo := &model.GzipInfo{
+		CRC32:                      binary.LittleEndian.Uint32(trailer),
+		ISize:                      isize,
+		EstimatedUncompressedBytes: int64(isize),
+		Reliable:                   true,
+		MultiMember:                isBGZF(header),
+	}
+
+	switch {
+	case gzipInfo.MultiMember:
+		gzipInfo.Reliable = false
+		gzipInfo.UnreliableReason = "multi-member gzip, ISIZE only covers the last member"
+		gzipInfo.EstimatedUncompressedBytes = max(int64(isize), size)
+	case size >= gzipISizeModulus:
+		gzipInfo.Reliable = false
+		gzipInfo.UnreliableReason = "file larger than 4 GiB, ISIZE has wrapped"
+		gzipInfo.EstimatedUncompressedBytes = unwrapISize(isize, size)
+	case int64(isize) < size/2:
+		// Deflate never expands data this much, so either ISIZE wrapped or
+		// the trailer belongs to the last of several members.
+		gzipInfo.Reliable = false
+		gzipInfo.UnreliableReason = "ISIZE much smaller than compressed size, output is larger than 4 GiB or multi-member"
+		gzipInfo.EstimatedUncompressedBytes = size
+	}
+	return gzipInfo, nil
+}
+
+// expectedSizeEnv adds the expected uncompressed size to the job env when the
+// gzip trailer gave a reliable estimate. Unreliable or missing estimates are
+// left out rather than passed as a misleading value.
+func expectedSizeEnv(env map[string]string, info model.FileInfo) map[string]string {
+	if info.Gzip == nil || !info.Gzip.Reliable || info.UncompressedBytes <= 0 {
+		return env
+	}
+	env = maps.Clone(env)
+	if env == nil {
+		env = map[string]string{}
+	}
+	env[constants.EXPECTED_SIZE_ENV] = strconv.FormatInt(info.UncompressedBytes, 10)
+	return env
+}
+
+// unwrapISize returns the smallest value congruent to ISIZE mod 2^32 that is
+// not smaller than the compressed size, a lower bound for the real output.
+func unwrapISize(isize uint32, compressedSize int64) int64 {
+	estimate := int64(isize)
+	for estimate < compressedSize {
+		estimate += gzipISizeModulus
+	}
+	return estimate
+}
+
+// isBGZF reports whether the first member carries the BGZF "BC" extra
+// subfield, which marks a file made of many independently compressed members.
+func isBGZF(header []byte) bool {
+	if len(header) < gzipHeaderLen+6 || header[3]&gzipExtraFlag == 0 {
+		return false
+	}
+	xlen := int(binary.LittleEndian.Uint16(header[gzipHeaderLen:]))
+	extra := header[gzipHeaderLen+2:]
+	if len(extra) > xlen {
+		extra = extra[:xlen]
+	}
+	for len(extra) >= 4 {
+		size := int(binary.LittleEndian.Uint16(extra[2:]))
+		if extra[0] == 'B' && extra[1] == 'C' {
+			return true
+		}
+		if len(extra) < 4+size {
+			break
+		}
+		extra = extra[4+size:]
+	}
+	return false
+}
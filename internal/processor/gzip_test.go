@@ -0,0 +1,157 @@
+package processor
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+	"go.uber.org/zap"
+)
+
+// gzipData compresses content into a single gzip member with the given
+// header extra field.
+func gzipData(t *testing.T, content []byte, extra []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Extra = extra
+	w.Write(content)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadGzipTrailer(t *testing.T) {
+	text := []byte(strings.Repeat("compressible line\n", 1000))
+	random := make([]byte, 4096)
+	for i := range random {
+		random[i] = byte(i * 7919 >> 3)
+	}
+	bgzf := []byte{'B', 'C', 2, 0, 0x1b, 0}
+
+	// A payload whose trailer claims far less output than the compressed
+	// size, as happens when ISIZE has wrapped.
+	shrunk := gzipData(t, random, nil)
+	copy(shrunk[len(shrunk)-4:], []byte{1, 0, 0, 0})
+
+	tests := []struct {
+		name        string
+		data        []byte
+		isize       uint32
+		reliable    bool
+		multiMember bool
+		errorMsg    string
+	}{
+		{name: "single member", data: gzipData(t, text, nil), isize: uint32(len(text)), reliable: true},
+		{name: "bgzf", data: gzipData(t, text, bgzf), isize: uint32(len(text)), multiMember: true},
+		{name: "isize smaller than file", data: shrunk, isize: 1},
+		{name: "not gzip", data: bytes.Repeat([]byte("x"), 100), errorMsg: "invalid gzip header"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := serveBytes(t, tt.data)
+			p := &Processor{logger: zap.NewNop()}
+			info, err := p.readGzipTrailer(context.Background(), server.URL, int64(len(tt.data)))
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("error = %v, want %q", err, tt.errorMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.ISize != tt.isize || info.Reliable != tt.reliable || info.MultiMember != tt.multiMember {
+				t.Errorf("got isize %d reliable %v multi-member %v, want %d %v %v",
+					info.ISize, info.Reliable, info.MultiMember, tt.isize, tt.reliable, tt.multiMember)
+			}
+			if info.EstimatedUncompressedBytes < int64(info.ISize) {
+				t.Errorf("estimate %d below ISIZE %d", info.EstimatedUncompressedBytes, info.ISize)
+			}
+		})
+	}
+}
+
+func TestUnwrapISize(t *testing.T) {
+	tests := []struct {
+		name       string
+		isize      uint32
+		compressed int64
+		want       int64
+	}{
+		{name: "already large enough", isize: 100, compressed: 50, want: 100},
+		{name: "one wrap", isize: 100, compressed: gzipISizeModulus, want: gzipISizeModulus + 100},
+		{name: "two wraps", isize: 0, compressed: gzipISizeModulus + 1, want: 2 * gzipISizeModulus},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unwrapISize(tt.isize, tt.compressed); got != tt.want {
+				t.Errorf("unwrapISize = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsBGZF(t *testing.T) {
+	header := func(flags byte, extra ...byte) []byte {
+		h := []byte{0x1f, 0x8b, 8, flags, 0, 0, 0, 0, 0, 0xff, byte(len(extra)), 0}
+		return append(h, extra...)
+	}
+	tests := []struct {
+		name   string
+		header []byte
+		want   bool
+	}{
+		{name: "bgzf subfield", header: header(gzipExtraFlag, 'B', 'C', 2, 0, 0, 0), want: true},
+		{name: "bgzf after other subfield", header: header(gzipExtraFlag, 'X', 'Y', 1, 0, 9, 'B', 'C', 2, 0, 0, 0), want: true},
+		{name: "other subfield", header: header(gzipExtraFlag, 'X', 'Y', 2, 0, 0, 0)},
+		{name: "extra flag unset", header: header(0, 'B', 'C', 2, 0, 0, 0)},
+		{name: "truncated subfield", header: header(gzipExtraFlag, 'X', 'Y', 9, 0, 0, 0)},
+		{name: "no extra field", header: header(0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isBGZF(tt.header); got != tt.want {
+				t.Errorf("isBGZF = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpectedSizeEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		info model.FileInfo
+		want map[string]string
+	}{
+		{
+			name: "reliable estimate",
+			info: model.FileInfo{Gzip: &model.GzipInfo{Reliable: true}, UncompressedBytes: 1234},
+			want: map[string]string{constants.EXPECTED_SIZE_ENV: "1234"},
+		},
+		{
+			name: "merged with payload env",
+			env:  map[string]string{constants.JOB_PAYLOAD_ENV: "{}"},
+			info: model.FileInfo{Gzip: &model.GzipInfo{Reliable: true}, UncompressedBytes: 1234},
+			want: map[string]string{constants.JOB_PAYLOAD_ENV: "{}", constants.EXPECTED_SIZE_ENV: "1234"},
+		},
+		{name: "unreliable estimate", info: model.FileInfo{Gzip: &model.GzipInfo{}, UncompressedBytes: 1234}},
+		{name: "trailer not read", info: model.FileInfo{UncompressedBytes: 1234}},
+		{name: "zero estimate", info: model.FileInfo{Gzip: &model.GzipInfo{Reliable: true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := expectedSizeEnv(tt.env, tt.info)
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("env = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package processor
+
+import (
+	"path"
+	"strings"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+)
+
+// maxLayers bounds how many suffixes are peeled off a file name, so names
+// such as "a.b.c.d.gz" do not produce arbitrary layer lists.
+const maxLayers = 3
+
+// parseLayers peels known suffixes off the file name and records the
+// compression/archive layers from outermost to innermost together with the
+// inner payload format. For example "data.tar.gz" yields layers
+// ["gzip", "tar"] and "data.json.gz" yields ["gzip"] with payload "json".
+// The Content-Disposition file name is used when the URL path has no suffix.
+func parseLayers(info *model.FileInfo) {
+	name := info.FileName
+	if path.Ext(name) == "" && info.DispositionFileName != "" {
+		name = info.DispositionFileName
+	}
+	var layers []string
+	for range maxLayers {
+		ext := path.Ext(name)
+		if ext == "" {
+			break
+		}
+		f, ok := formatByExtension(ext)
+		if !ok {
+			break
+		}
+		if !f.layer {
+			info.PayloadFormat = f.name
+			break
+		}
+
+		layers = append(layers, f.name)
+		if strings.EqualFold(ext, ".tgz") {
+			layers = append(layers, "tar")
+		}
+		name = strings.TrimSuffix(name, ext)
+	}
+	info.Layers = layers
+}
+
+// applyDetectedLayer uses the sniffed or Content-Type derived format when the
+// file name carried no usable suffixes.
+func applyDetectedLayer(info *model.FileInfo) {
+	if len(info.Layers) > 0 || info.PayloadFormat != "" || info.DetectedFormat == "" {
+		return
+	}
+	if f, ok := formatByExtension(info.FileExtension); ok && f.layer {
+		info.Layers = []string{f.name}
+		return
+	}
+	info.PayloadFormat = info.DetectedFormat
+}
@@ -0,0 +1,78 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/routing"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// buildPayload builds and validates the JSON job payload for rules that take
+// one, returning the args and env that carry it. Payloads larger than
+// JOB_PAYLOAD_MAX_INLINE_BYTES are written to GCS and only their gs:// path
+// is passed; in dry-run mode the path is planned but nothing is written.
+// Rules in positional mode get no payload.
+func (p *Processor) buildPayload(ctx context.Context, request *model.FileInfo, rule *routing.Rule, shards *model.ShardManifest, decision *model.Decision) ([]string, map[string]string, error) {
+	if rule.Payload == constants.PAYLOAD_POSITIONAL {
+		return nil, nil, nil
+	}
+	decision.Payload = rule.Payload
+
+	payload := model.NewJobPayload(*request, rule.Name, rule.Job, shards)
+	if err := payload.Validate(); err != nil {
+		return nil, nil, err
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to encode job payload: %v", err)
+	}
+
+	value, envName := string(data), constants.JOB_PAYLOAD_ENV
+	if len(data) > constants.JOB_PAYLOAD_MAX_INLINE_BYTES {
+		objectPath := fmt.Sprintf("%s/%s/%s.json", constants.JOB_PAYLOAD_PREFIX, p.traceId, uuid.NewString())
+		value = fmt.Sprintf("gs://%s/%s", constants.HARDCODED_BUCKET_NAME, objectPath)
+		if !p.config.DryRun {
+			if value, err = p.gcs.WriteObject(ctx, objectPath, data, constants.APPLICATION_JSON); err != nil {
+				return nil, nil, err
+			}
+		}
+		envName = constants.JOB_PAYLOAD_PATH_ENV
+		decision.PayloadPath = value
+
+		p.logger.Info("job payload stored in gcs",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", p.traceId),
+			zap.String("fileUrl", request.FIleUrl),
+			zap.String("payloadPath", value),
+			zap.Int("payloadBytes", len(data)))
+	}
+
+	if rule.Payload == constants.PAYLOAD_ARG {
+		return []string{value}, nil, nil
+	}
+	return nil, map[string]string{envName: value}, nil
+}
+
+// withEnv returns a copy of the profile with extra env vars set, leaving the
+// rule's shared profile untouched.
+func withEnv(profile *model.ResourceProfile, env map[string]string) *model.ResourceProfile {
+	if len(env) == 0 {
+		return profile
+	}
+	merged := model.ResourceProfile{}
+	if profile != nil {
+		merged = *profile
+	}
+	merged.Env = maps.Clone(merged.Env)
+	if merged.Env == nil {
+		merged.Env = map[string]string{}
+	}
+	maps.Copy(merged.Env, env)
+	return &merged
+}
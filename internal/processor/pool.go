@@ -0,0 +1,70 @@
+package processor
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// hostLimiter caps the number of files processed concurrently per host so a
+// large batch from one vendor does not hammer a single server.
+type hostLimiter struct {
+	limit int
+	mu    sync.Mutex
+	slots map[string]chan struct{}
+}
+
+// newHostLimiter returns a limiter allowing limit concurrent files per host.
+// A limit of zero or less disables the per-host cap.
+func newHostLimiter(limit int) *hostLimiter {
+	return &hostLimiter{limit: limit, slots: map[string]chan struct{}{}}
+}
+
+// acquire blocks until a slot for the host is free. It returns false when
+// the context is cancelled first.
+func (h *hostLimiter) acquire(ctx context.Context, host string) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if h.limit <= 0 {
+		return true
+	}
+
+	select {
+	case h.slot(host) <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// release frees the slot taken by acquire.
+func (h *hostLimiter) release(host string) {
+	if h.limit <= 0 {
+		return
+	}
+	<-h.slot(host)
+}
+
+func (h *hostLimiter) slot(host string) chan struct{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	slot, ok := h.slots[host]
+	if !ok {
+		slot = make(chan struct{}, h.limit)
+		h.slots[host] = slot
+	}
+	return slot
+}
+
+// hostOf returns the lower-cased host of the URL, or the raw URL when it
+// cannot be parsed so that invalid URLs still get a slot of their own.
+func hostOf(rawURL string) string {
+	parsedUrl, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return strings.ToLower(parsedUrl.Hostname())
+}
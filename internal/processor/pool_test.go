@@ -0,0 +1,115 @@
+package processor
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHostLimiter(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   int
+		held    []string // hosts acquired before the call under test
+		host    string
+		blocked bool
+	}{
+		{name: "free slot", limit: 1, host: "a.example"},
+		{name: "limit reached", limit: 1, held: []string{"a.example"}, host: "a.example", blocked: true},
+		{name: "other host unaffected", limit: 1, held: []string{"a.example"}, host: "b.example"},
+		{name: "second slot", limit: 2, held: []string{"a.example"}, host: "a.example"},
+		{name: "unlimited", limit: 0, held: []string{"a.example", "a.example"}, host: "a.example"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHostLimiter(tt.limit)
+			for _, host := range tt.held {
+				if !h.acquire(context.Background(), host) {
+					t.Fatalf("acquire %s failed", host)
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			if acquired := h.acquire(ctx, tt.host); acquired == tt.blocked {
+				t.Errorf("acquired = %v, want %v", acquired, !tt.blocked)
+			}
+		})
+	}
+}
+
+func TestHostLimiterReleaseUnblocksWaiter(t *testing.T) {
+	h := newHostLimiter(1)
+	if !h.acquire(context.Background(), "a.example") {
+		t.Fatal("first acquire failed")
+	}
+
+	acquired := make(chan bool)
+	go func() {
+		acquired <- h.acquire(context.Background(), "a.example")
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("waiter acquired the slot before it was released")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	h.release("a.example")
+	select {
+	case ok := <-acquired:
+		if !ok {
+			t.Error("waiter failed to acquire the released slot")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiter still blocked after release")
+	}
+}
+
+func TestHostLimiterCancellation(t *testing.T) {
+	h := newHostLimiter(1)
+	h.acquire(context.Background(), "a.example")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	acquired := make(chan bool)
+	go func() {
+		acquired <- h.acquire(ctx, "a.example")
+	}()
+	cancel()
+
+	select {
+	case ok := <-acquired:
+		if ok {
+			t.Error("acquire succeeded after cancellation")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("acquire did not return after cancellation")
+	}
+
+	// A cancelled context never takes a slot, even when one is free.
+	h.release("a.example")
+	if h.acquire(ctx, "a.example") {
+		t.Error("acquire with a cancelled context took a free slot")
+	}
+	if !h.acquire(context.Background(), "a.example") {
+		t.Error("slot was not left free")
+	}
+}
+
+func TestHostOf(t *testing.T) {
+	tests := []struct {
+		url  string
+		host string
+	}{
+		{url: "https://Data.Example.com/a.csv", host: "data.example.com"},
+		{url: "https://data.example.com:8443/a.csv", host: "data.example.com"},
+		{url: "://bad", host: "://bad"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if host := hostOf(tt.url); host != tt.host {
+				t.Errorf("host = %q, want %q", host, tt.host)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+	"go.uber.org/zap"
+)
+
+// HTTPStatusError reports a probe request that was answered with a non-2xx
+// status, such as a 404 or 403 error page served instead of the file.
+type HTTPStatusError struct {
+	Method     string
+	StatusCode int
+	Status     string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("%s request returned %s", e.Method, e.Status)
+}
+
+// probeResult holds the response metadata gathered by the probe chain.
+type probeResult struct {
+	header         http.Header
+	statusCode     int
+	finalUrl       string
+	sizeBytes      int64 // -1 when the size could not be determined
+	sizeMethod     string
+	rangeSupported bool
+}
+
+// setResponse records the first successful response, whose headers describe
+// the file, and the URL it was served from after redirects.
+func (r *probeResult) setResponse(resp *http.Response) {
+	if r.header != nil {
+		return
+	}
+	r.header = resp.Header
+	r.statusCode = resp.StatusCode
+	r.finalUrl = resp.Request.URL.String()
+}
+
+func isSuccess(statusCode int) bool {
+	return statusCode >= 200 && statusCode <= 299
+}
+
+// probe determines the file size with a fallback chain: a HEAD request, then
+// a GET for the first byte whose Content-Range carries the total size, then
+// a streaming GET that counts the body up to a cap. Vendors that reject HEAD
+// or send chunked responses without Content-Length are handled by the later
+// steps. When every step ran but none produced a size, the result is returned
+// with sizeBytes -1; an error is returned only if no request succeeded. When a
+// request was answered with a non-2xx status the error wraps an
+// *HTTPStatusError, preferring the GET responses over HEAD.
+func (p *Processor) probe(ctx context.Context, fileUrl string) (probeResult, error) {
+	result := probeResult{sizeBytes: -1}
+
+	headErr := p.probeHead(ctx, fileUrl, &result)
+	if result.sizeBytes >= 0 {
+		return result, nil
+	}
+
+	rangeErr := p.probeRangeGet(ctx, fileUrl, &result)
+	if result.sizeBytes >= 0 {
+		return result, nil
+	}
+
+	streamErr := p.probeStreamingGet(ctx, fileUrl, &result)
+	if result.sizeBytes >= 0 {
+		return result, nil
+	}
+
+	if result.header == nil {
+		return result, fmt.Errorf("all probes failed: %w", errors.Join(streamErr, rangeErr, headErr))
+	}
+
+	p.logger.Warn("file size unknown after all probes",
+		zap.String("applicationName", constants.APPLICATION_NAME),
+		zap.String("traceId", p.traceId),
+		zap.String("fileUrl", fileUrl),
+		zap.NamedError("headError", headErr),
+		zap.NamedError("rangeError", rangeErr),
+		zap.NamedError("streamError", streamErr))
+	return result, nil
+}
+
+// probeHead issues a HEAD request and reads Content-Length.
+func (p *Processor) probeHead(ctx context.Context, fileUrl string, result *probeResult) error {
+	req, err := http.NewRequestWithContext(ctx, constants.HEAD, fileUrl, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create HEAD request: %v", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute HEAD request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if !isSuccess(resp.StatusCode) {
+		return &HTTPStatusError{Method: constants.HEAD, StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	result.setResponse(resp)
+	result.rangeSupported = resp.Header.Get(constants.RANGE_SUPPORTED) == constants.BYTES
+
+	size, err := strconv.ParseInt(resp.Header.Get(constants.CONTENT_LENGTH), 10, 64)
+	if err != nil || size < 0 {
+		return fmt.Errorf("HEAD response has no usable Content-Length")
+	}
+	result.sizeBytes = size
+	result.sizeMethod = constants.SIZE_FROM_HEAD
+	return nil
+}
+
+// probeRangeGet requests the first byte and reads the total size from the
+// Content-Range header. A server answering 206 supports ranges regardless of
+// whether it advertised Accept-Ranges.
+func (p *Processor) probeRangeGet(ctx context.Context, fileUrl string, result *probeResult) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileUrl, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create range request: %v", err)
+	}
+	req.Header.Set(constants.RANGE, "bytes=0-0")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute range request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		result.rangeSupported = true
+		result.setResponse(resp)
+		size, err := parseContentRangeTotal(resp.Header.Get(constants.CONTENT_RANGE))
+		if err != nil {
+			return err
+		}
+		result.sizeBytes = size
+		result.sizeMethod = constants.SIZE_FROM_RANGE_GET
+		return nil
+	case http.StatusOK:
+		result.setResponse(resp)
+		if size := resp.ContentLength; size >= 0 {
+			result.sizeBytes = size
+			result.sizeMethod = constants.SIZE_FROM_RANGE_GET
+			return nil
+		}
+		return fmt.Errorf("range request ignored and response has no Content-Length")
+	default:
+		if !isSuccess(resp.StatusCode) {
+			return &HTTPStatusError{Method: http.MethodGet, StatusCode: resp.StatusCode, Status: resp.Status}
+		}
+		result.setResponse(resp)
+		return fmt.Errorf("range request returned %s", resp.Status)
+	}
+}
+
+// probeStreamingGet downloads the body up to PROBE_STREAM_MAX_BYTES and
+// counts it. Files larger than the cap are left with an unknown size.
+func (p *Processor) probeStreamingGet(ctx context.Context, fileUrl string, result *probeResult) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileUrl, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create streaming request: %v", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute streaming request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if !isSuccess(resp.StatusCode) {
+		return &HTTPStatusError{Method: http.MethodGet, StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	result.setResponse(resp)
+
+	n, err := io.Copy(io.Discard, io.LimitReader(resp.Body, constants.PROBE_STREAM_MAX_BYTES+1))
+	if err != nil {
+		return fmt.Errorf("failed to read streaming response: %v", err)
+	}
+	if n > constants.PROBE_STREAM_MAX_BYTES {
+		return fmt.Errorf("body exceeds the streaming probe cap of %d bytes", int64(constants.PROBE_STREAM_MAX_BYTES))
+	}
+	result.sizeBytes = n
+	result.sizeMethod = constants.SIZE_FROM_STREAMING_GET
+	return nil
+}
+
+// parseContentRangeTotal extracts the complete length from a Content-Range
+// header such as "bytes 0-0/1234".
+func parseContentRangeTotal(contentRange string) (int64, error) {
+	_, total, ok := strings.Cut(contentRange, "/")
+	if !ok || total == "*" {
+		return 0, fmt.Errorf("Content-Range %q has no total size", contentRange)
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(total), 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid Content-Range %q", contentRange)
+	}
+	return size, nil
+}
+
+// applyResponseMetadata copies the integrity and naming headers of the probed
+// response onto the file info for downstream jobs.
+func applyResponseMetadata(info *model.FileInfo, result probeResult) {
+	header := result.header
+	info.StatusCode = result.statusCode
+	info.FinalUrl = result.finalUrl
+	info.ETag = header.Get(constants.ETAG)
+	info.LastModified = header.Get(constants.LAST_MODIFIED)
+	info.ContentEncoding = header.Get(constants.CONTENT_ENCODING)
+
+	if disposition := header.Get(constants.CONTENT_DISPOSITION); disposition != "" {
+		if _, params, err := mime.ParseMediaType(disposition); err == nil {
+			info.DispositionFileName = path.Base(params["filename"])
+			if info.DispositionFileName == "." {
+				info.DispositionFileName = ""
+			}
+		}
+	}
+
+	checksums := map[string]string{}
+	for _, value := range header.Values(constants.X_GOOG_HASH) {
+		for _, part := range strings.Split(value, ",") {
+			if name, digest, ok := strings.Cut(strings.TrimSpace(part), "="); ok {
+				checksums[name] = digest
+			}
+		}
+	}
+	if md5 := header.Get(constants.CONTENT_MD5); md5 != "" {
+		checksums["md5"] = md5
+	}
+	if len(checksums) > 0 {
+		info.Checksums = checksums
+	}
+}
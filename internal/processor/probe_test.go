@@ -0,0 +1,158 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+	"go.uber.org/zap"
+)
+
+// chunked writes the body without a Content-Length.
+func chunked(w http.ResponseWriter, body string) {
+	w.WriteHeader(http.StatusOK)
+	w.(http.Flusher).Flush()
+	fmt.Fprint(w, body)
+}
+
+func TestProbe(t *testing.T) {
+	body := strings.Repeat("x", 1234)
+
+	tests := []struct {
+		name           string
+		head           http.HandlerFunc
+		rangeGet       http.HandlerFunc
+		get            http.HandlerFunc
+		size           int64
+		method         string
+		rangeSupported bool
+		calls          string // requests issued, in order
+		statusCode     int    // status wrapped in the error, 0 when the probe succeeds
+	}{
+		{
+			name: "head",
+			head: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(constants.CONTENT_LENGTH, "1234")
+				w.Header().Set(constants.RANGE_SUPPORTED, constants.BYTES)
+			},
+			size: 1234, method: constants.SIZE_FROM_HEAD, rangeSupported: true, calls: "head",
+		},
+		{
+			name: "head rejected, range get",
+			head: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusMethodNotAllowed) },
+			rangeGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(constants.CONTENT_RANGE, "bytes 0-0/1234")
+				w.WriteHeader(http.StatusPartialContent)
+				fmt.Fprint(w, "x")
+			},
+			size: 1234, method: constants.SIZE_FROM_RANGE_GET, rangeSupported: true, calls: "head range",
+		},
+		{
+			name:     "range ignored with length",
+			head:     func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusForbidden) },
+			rangeGet: func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, body) },
+			size:     1234, method: constants.SIZE_FROM_RANGE_GET, calls: "head range",
+		},
+		{
+			name:     "chunked responses, streaming get",
+			head:     func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusMethodNotAllowed) },
+			rangeGet: func(w http.ResponseWriter, r *http.Request) { chunked(w, body) },
+			get:      func(w http.ResponseWriter, r *http.Request) { chunked(w, body) },
+			size:     1234, method: constants.SIZE_FROM_STREAMING_GET, calls: "head range get",
+		},
+		{
+			name: "range without total, streaming get",
+			head: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusMethodNotAllowed) },
+			rangeGet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(constants.CONTENT_RANGE, "bytes 0-0/*")
+				w.WriteHeader(http.StatusPartialContent)
+				fmt.Fprint(w, "x")
+			},
+			get:  func(w http.ResponseWriter, r *http.Request) { chunked(w, body) },
+			size: 1234, method: constants.SIZE_FROM_STREAMING_GET, rangeSupported: true, calls: "head range get",
+		},
+		{
+			name:       "not found",
+			head:       func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNotFound) },
+			rangeGet:   func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNotFound) },
+			get:        func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNotFound) },
+			calls:      "head range get",
+			statusCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodHead:
+					calls = append(calls, "head")
+					tt.head(w, r)
+				case r.Header.Get(constants.RANGE) != "":
+					calls = append(calls, "range")
+					tt.rangeGet(w, r)
+				default:
+					calls = append(calls, "get")
+					tt.get(w, r)
+				}
+			}))
+			defer server.Close()
+
+			p := &Processor{logger: zap.NewNop()}
+			result, err := p.probe(context.Background(), server.URL)
+			if got := strings.Join(calls, " "); got != tt.calls {
+				t.Errorf("requests = %q, want %q", got, tt.calls)
+			}
+			if tt.statusCode != 0 {
+				var statusErr *HTTPStatusError
+				if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.statusCode {
+					t.Fatalf("error = %v, want status %d", err, tt.statusCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("probe failed after %v: %v", calls, err)
+			}
+			if result.sizeBytes != tt.size || result.sizeMethod != tt.method {
+				t.Errorf("got %d bytes via %q, want %d via %q", result.sizeBytes, result.sizeMethod, tt.size, tt.method)
+			}
+			if result.rangeSupported != tt.rangeSupported {
+				t.Errorf("rangeSupported = %v, want %v", result.rangeSupported, tt.rangeSupported)
+			}
+			if result.statusCode != http.StatusOK && result.statusCode != http.StatusPartialContent {
+				t.Errorf("statusCode = %d", result.statusCode)
+			}
+		})
+	}
+}
+
+func TestParseContentRangeTotal(t *testing.T) {
+	tests := []struct {
+		header string
+		size   int64
+		ok     bool
+	}{
+		{header: "bytes 0-0/1234", size: 1234, ok: true},
+		{header: "bytes 0-0/0", size: 0, ok: true},
+		{header: "bytes 0-0/*"},
+		{header: "bytes 0-0"},
+		{header: "bytes 0-0/-5"},
+		{header: "bytes 0-0/abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			size, err := parseContentRangeTotal(tt.header)
+			if (err == nil) != tt.ok {
+				t.Fatalf("error = %v, want ok %v", err, tt.ok)
+			}
+			if size != tt.size {
+				t.Errorf("size = %d, want %d", size, tt.size)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/launcher"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// enqueueContractFile records the file in the contract file queue as QUEUED
+// under a new contract ID, which is kept on the decision so the entry can be
+// moved through DISPATCHED to DONE or FAILED as the job progresses. The job
+// request is stored with the entry so the dispatcher can launch it later.
+func (p *Processor) enqueueContractFile(ctx context.Context, request *model.FileInfo, decision *model.Decision, priority int64, jobRequest launcher.JobRequest) error {
+	arguments, err := json.Marshal(model.Arguments{
+		TraceId:        p.traceId,
+		FIleUrl:        request.FIleUrl,
+		FileName:       request.FileName,
+		RangeSupported: request.RangeSupported,
+		FileExtension:  request.FileExtension,
+		FileSize:       request.FileSize,
+		ContentType:    request.ContentType,
+	})
+	if err != nil {
+		return err
+	}
+	serializedRequest, err := json.Marshal(jobRequest)
+	if err != nil {
+		return err
+	}
+
+	contractId := uuid.NewString()
+	err = p.queue.ContractFileQueue(ctx, model.ContractFileEvent{
+		TraceID:      p.traceId,
+		ContractID:   contractId,
+		Status:       constants.QUEUE_STATUS_QUEUED,
+		Timestamp:    time.Now(),
+		FunctionName: constants.APPLICATION_NAME,
+		Arguments:    string(arguments),
+		Environment:  constants.ENVIRONMENT,
+		JobName:      jobRequest.JobName,
+		Priority:     priority,
+		JobRequest:   string(serializedRequest),
+	})
+	if err != nil {
+		return err
+	}
+	decision.ContractId = contractId
+
+	p.audit.LogAuditData(ctx, model.AuditEvent{
+		TraceID:      p.traceId,
+		ContractId:   contractId,
+		Event:        constants.CONTRACT_FILE_QUEUED,
+		Status:       constants.COMPLETED,
+		Timestamp:    time.Now(),
+		FileUrl:      request.FIleUrl,
+		FunctionName: constants.APPLICATION_NAME,
+		Message:      string(arguments),
+	})
+	return nil
+}
+
+// updateContractFile moves the file's contract file queue entry to status.
+// A failed update is audited but does not fail the file, since the job has
+// already been launched or has already failed.
+func (p *Processor) updateContractFile(ctx context.Context, fileUrl string, contractId string, status string) {
+	err := p.queue.UpdateContractFileStatus(ctx, p.traceId, contractId, status)
+	if err == nil {
+		p.logger.Info("contract file queue entry updated",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", p.traceId),
+			zap.String("contractId", contractId),
+			zap.String("status", status))
+		return
+	}
+
+	p.audit.LogAuditData(ctx, model.AuditEvent{
+		TraceID:      p.traceId,
+		ContractId:   contractId,
+		Event:        constants.CONTRACT_QUEUE_UPDATE_FAILED,
+		Status:       constants.FAILED,
+		Timestamp:    time.Now(),
+		FileUrl:      fileUrl,
+		FunctionName: constants.APPLICATION_NAME,
+		Message:      err.Error(),
+	})
+}
+
+// failUnclaimedContractFile audits a claim that failed and moves the entry to
+// FAILED, so the dispatcher does not later launch a file reported as failed.
+// Claims that lost to a concurrent update were already retried by the queue.
+func (p *Processor) failUnclaimedContractFile(ctx context.Context, fileUrl string, contractId string, claimErr error) {
+	p.audit.LogAuditData(ctx, model.AuditEvent{
+		TraceID:      p.traceId,
+		ContractId:   contractId,
+		Event:        constants.CONTRACT_QUEUE_UPDATE_FAILED,
+		Status:       constants.FAILED,
+		Timestamp:    time.Now(),
+		FileUrl:      fileUrl,
+		FunctionName: constants.APPLICATION_NAME,
+		Message:      claimErr.Error(),
+	})
+	p.updateContractFile(ctx, fileUrl, contractId, constants.QUEUE_STATUS_FAILED)
+}
+
+// recordContractFileOperation stores the operation of the launched job on the
+// file's contract file queue entry, which was claimed as DISPATCHED before
+// the launch. A failed update is audited like a failed status update.
+func (p *Processor) recordContractFileOperation(ctx context.Context, fileUrl string, contractId string, operation string) {
+	if operation == "" {
+		return
+	}
+	if err := p.queue.SetContractFileOperation(ctx, p.traceId, contractId, operation); err != nil {
+		p.audit.LogAuditData(ctx, model.AuditEvent{
+			TraceID:      p.traceId,
+			ContractId:   contractId,
+			Event:        constants.CONTRACT_QUEUE_UPDATE_FAILED,
+			Status:       constants.FAILED,
+			Timestamp:    time.Now(),
+			FileUrl:      fileUrl,
+			FunctionName: constants.APPLICATION_NAME,
+			Message:      err.Error(),
+		})
+	}
+}
+
+// contractFileDone returns the tracker callback that settles the queue entry
+// once the execution reaches a terminal state and then runs Config.Dispatch
+// for the files waiting on the freed slot. An execution whose tracking timed
+// out is still running as far as we know, so its entry stays DISPATCHED.
+func (p *Processor) contractFileDone(ctx context.Context, fileUrl string, contractId string) func(outcome model.ExecutionOutcome) {
+	return func(outcome model.ExecutionOutcome) {
+		ctx := context.WithoutCancel(ctx)
+		switch outcome.State {
+		case constants.EXECUTION_SUCCEEDED:
+			p.updateContractFile(ctx, fileUrl, contractId, constants.QUEUE_STATUS_DONE)
+		case constants.EXECUTION_FAILED, constants.EXECUTION_CANCELLED:
+			p.updateContractFile(ctx, fileUrl, contractId, constants.QUEUE_STATUS_FAILED)
+		default:
+			return
+		}
+		if p.config.Dispatch != nil {
+			p.config.Dispatch(ctx)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"mime"
+	"strings"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+	"go.uber.org/zap"
+)
+
+// fileFormat describes a format the prober can recognise by extension,
+// Content-Type or leading signature bytes. Layer formats are compression or
+// archive containers wrapping an inner payload.
+type fileFormat struct {
+	name         string
+	extension    string
+	extensions   []string
+	contentTypes []string
+	signature    func(data []byte) bool
+	layer        bool
+}
+
+// fileFormats is ordered so that container signatures are checked before the
+// loose JSON heuristic.
+var fileFormats = []fileFormat{
+	{
+		name:         "gzip",
+		layer:        true,
+		extension:    constants.GZ,
+		extensions:   []string{constants.GZ, ".tgz"},
+		contentTypes: []string{"application/gzip", "application/x-gzip"},
+		signature:    hasPrefix(0x1f, 0x8b),
+	},
+	{
+		name:         "zip",
+		layer:        true,
+		extension:    constants.ZIP,
+		extensions:   []string{constants.ZIP},
+		contentTypes: []string{"application/zip", "application/x-zip-compressed"},
+		signature: func(data []byte) bool {
+			return hasPrefix('P', 'K', 0x03, 0x04)(data) || hasPrefix('P', 'K', 0x05, 0x06)(data) || hasPrefix('P', 'K', 0x07, 0x08)(data)
+		},
+	},
+	{
+		name:         "zstd",
+		layer:        true,
+		extension:    ".zst",
+		extensions:   []string{".zst", ".zstd"},
+		contentTypes: []string{"application/zstd"},
+		signature:    hasPrefix(0x28, 0xb5, 0x2f, 0xfd),
+	},
+	{
+		name:         "bzip2",
+		layer:        true,
+		extension:    ".bz2",
+		extensions:   []string{".bz2"},
+		contentTypes: []string{"application/x-bzip2"},
+		signature:    hasPrefix('B', 'Z', 'h'),
+	},
+	{
+		name:         "xz",
+		layer:        true,
+		extension:    ".xz",
+		extensions:   []string{".xz"},
+		contentTypes: []string{"application/x-xz"},
+		signature:    hasPrefix(0xfd, '7', 'z', 'X', 'Z', 0x00),
+	},
+	{
+		name:         "tar",
+		layer:        true,
+		extension:    ".tar",
+		extensions:   []string{".tar"},
+		contentTypes: []string{"application/x-tar"},
+		signature: func(data []byte) bool {
+			// POSIX and GNU tar headers carry "ustar" at offset 257.
+			return len(data) >= 262 && bytes.Equal(data[257:262], []byte("ustar"))
+		},
+	},
+	{
+		name:         "parquet",
+		extension:    ".parquet",
+		extensions:   []string{".parquet"},
+		contentTypes: []string{"application/vnd.apache.parquet", "application/x-parquet"},
+		signature:    hasPrefix('P', 'A', 'R', '1'),
+	},
+	{
+		name:         "json",
+		extension:    constants.JSON,
+		extensions:   []string{constants.JSON, ".ndjson", ".jsonl"},
+		contentTypes: []string{"application/json", "application/x-ndjson", "text/json"},
+		signature: func(data []byte) bool {
+			data = bytes.TrimPrefix(data, []byte{0xef, 0xbb, 0xbf})
+			data = bytes.TrimLeft(data, " \t\r\n")
+			return len(data) > 0 && (data[0] == '{' || data[0] == '[')
+		},
+	},
+	{
+		name:         "csv",
+		extension:    ".csv",
+		extensions:   []string{".csv"},
+		contentTypes: []string{"text/csv"},
+	},
+	{
+		name:         "tsv",
+		extension:    ".tsv",
+		extensions:   []string{".tsv"},
+		contentTypes: []string{"text/tab-separated-values"},
+	},
+}
+
+func hasPrefix(signature ...byte) func(data []byte) bool {
+	return func(data []byte) bool {
+		return bytes.HasPrefix(data, signature)
+	}
+}
+
+// formatByExtension looks up a format by file extension.
+func formatByExtension(ext string) (fileFormat, bool) {
+	for _, f := range fileFormats {
+		for _, e := range f.extensions {
+			if strings.EqualFold(e, ext) {
+				return f, true
+			}
+		}
+	}
+	return fileFormat{}, false
+}
+
+// formatByContentType looks up a format by media type, ignoring parameters.
+func formatByContentType(contentType string) (fileFormat, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fileFormat{}, false
+	}
+	for _, f := range fileFormats {
+		for _, ct := range f.contentTypes {
+			if mediaType == ct {
+				return f, true
+			}
+		}
+	}
+	return fileFormat{}, false
+}
+
+// formatBySignature matches the leading bytes of a file against known signatures.
+func formatBySignature(data []byte) (fileFormat, bool) {
+	for _, f := range fileFormats {
+		if f.signature != nil && f.signature(data) {
+			return f, true
+		}
+	}
+	return fileFormat{}, false
+}
+
+// detectFormat records the file format and how it was determined. The URL
+// extension wins, then the Content-Type; when both are ambiguous and the
+// server supports ranges, the first bytes of the file are fetched and matched
+// against known signatures. A format found this way also supplies the file
+// extension used for routing.
+func (p *Processor) detectFormat(ctx context.Context, info *model.FileInfo) {
+	if f, ok := formatByExtension(info.FileExtension); ok {
+		info.DetectedFormat = f.name
+		info.DetectionMethod = constants.DETECTED_BY_EXTENSION
+		return
+	}
+
+	if f, ok := formatByContentType(info.ContentType); ok {
+		info.DetectedFormat = f.name
+		info.DetectionMethod = constants.DETECTED_BY_CONTENT_TYPE
+		info.FileExtension = f.extension
+		return
+	}
+
+	if !info.RangeSupported {
+		return
+	}
+
+	data, err := p.fetchRange(ctx, info.FIleUrl, 0, constants.SNIFF_BYTES-1)
+	if err != nil {
+		p.logger.Warn("unable to sniff file content",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", p.traceId),
+			zap.String("fileUrl", info.FIleUrl),
+			zap.Error(err))
+		return
+	}
+
+	if f, ok := formatBySignature(data); ok {
+		info.DetectedFormat = f.name
+		info.DetectionMethod = constants.DETECTED_BY_MAGIC_BYTES
+		info.FileExtension = f.extension
+	}
+}
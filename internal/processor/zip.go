@@ -0,0 +1,316 @@
+package processor
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+	"go.uber.org/zap"
+)
+
+// ZIP record signatures and sizes, see APPNOTE.TXT sections 4.3.12 - 4.3.16.
+const (
+	zipCentralHeaderSig   = 0x02014b50
+	zipEOCDSig            = 0x06054b50
+	zip64EOCDSig          = 0x06064b50
+	zip64LocatorSig       = 0x07064b50
+	zipEOCDLen            = 22
+	zip64EOCDLen          = 56
+	zip64LocatorLen       = 20
+	zipCentralHeaderLen   = 46
+	zipMaxCommentLen      = 0xffff
+	zip64ExtraID          = 0x0001
+	zipEncryptedFlag      = 0x1
+	zipAESEncryptedMethod = 99
+	uint16Max             = 0xffff
+	uint32Max             = 0xffffffff
+)
+
+// zipMethodNames maps the common compression method ids to readable names.
+var zipMethodNames = map[uint16]string{
+	0:  "store",
+	8:  "deflate",
+	9:  "deflate64",
+	12: "bzip2",
+	14: "lzma",
+	93: "zstd",
+	95: "xz",
+	99: "aes",
+}
+
+// zipDirectory is the location of the central directory as read from the
+// end-of-central-directory records.
+type zipDirectory struct {
+	entries int64
+	size    int64
+	offset  int64
+	zip64   bool
+}
+
+// isZip reports whether the outermost layer of the file is a ZIP archive.
+func isZip(info model.FileInfo) bool {
+	return len(info.Layers) > 0 && info.Layers[0] == "zip"
+}
+
+// inspectZip reads the end-of-central-directory record and the central
+// directory with range requests and records a summary of the archive on
+// the file info, without downloading the archive itself.
+func (p *Processor) inspectZip(ctx context.Context, info *model.FileInfo) {
+	if !isZip(*info) || !info.RangeSupported || info.SizeBytes < zipEOCDLen {
+		return
+	}
+
+	zipInfo, err := p.readZipCentralDirectory(ctx, info.FIleUrl, info.SizeBytes)
+	if err != nil {
+		p.logger.Warn("unable to inspect zip central directory",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", p.traceId),
+			zap.String("fileUrl", info.FIleUrl),
+			zap.Error(err))
+		return
+	}
+	info.Zip = zipInfo
+	info.UncompressedBytes = zipInfo.TotalUncompressedBytes
+
+	p.logger.Info("zip central directory inspected",
+		zap.String("applicationName", constants.APPLICATION_NAME),
+		zap.String("traceId", p.traceId),
+		zap.String("fileUrl", info.FIleUrl),
+		zap.Int64("entryCount", zipInfo.EntryCount),
+		zap.Int64("totalUncompressedBytes", zipInfo.TotalUncompressedBytes),
+		zap.Bool("zip64", zipInfo.Zip64),
+		zap.Bool("encrypted", zipInfo.Encrypted))
+}
+
+// readZipCentralDirectory locates and parses the central directory of the
+// remote archive of the given size.
+func (p *Processor) readZipCentralDirectory(ctx context.Context, fileUrl string, size int64) (*model.ZipInfo, error) {
+	tailLen := min(size, int64(zipEOCDLen+zipMaxCommentLen+zip64LocatorLen))
+	tailStart := size - tailLen
+	tail, err := p.fetchRange(ctx, fileUrl, tailStart, size-1)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := p.parseZipEOCD(ctx, fileUrl, tail, tailStart)
+	if err != nil {
+		return nil, err
+	}
+
+	zipInfo := &model.ZipInfo{EntryCount: dir.entries, Zip64: dir.zip64}
+	if dir.size == 0 {
+		return zipInfo, nil
+	}
+	if dir.size > size || dir.offset > size-dir.size {
+		return nil, fmt.Errorf("central directory at %d+%d exceeds archive size %d", dir.offset, dir.size, size)
+	}
+	if dir.size > constants.ZIP_MAX_CENTRAL_DIRECTORY_BYTES {
+		return nil, fmt.Errorf("central directory of %d bytes exceeds the inspection limit", dir.size)
+	}
+
+	// The central directory is usually already part of the fetched tail.
+	var directory []byte
+	if dir.offset >= tailStart {
+		directory, err = zipSlice(tail, dir.offset-tailStart, dir.size)
+		if err != nil {
+			return nil, fmt.Errorf("central directory at %d+%d: %v", dir.offset, dir.size, err)
+		}
+	} else {
+		directory, err = p.fetchRange(ctx, fileUrl, dir.offset, dir.offset+dir.size-1)
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(directory)) != dir.size {
+			return nil, fmt.Errorf("truncated central directory: read %d of %d bytes", len(directory), dir.size)
+		}
+	}
+
+	if err := parseZipCentralDirectory(directory, zipInfo); err != nil {
+		return nil, err
+	}
+	return zipInfo, nil
+}
+
+// parseZipEOCD finds the end-of-central-directory record in the tail of the
+// archive, following the ZIP64 locator when the classic record overflows.
+func (p *Processor) parseZipEOCD(ctx context.Context, fileUrl string, tail []byte, tailStart int64) (zipDirectory, error) {
+	pos := findZipEOCD(tail)
+	if pos < 0 {
+		return zipDirectory{}, fmt.Errorf("end of central directory record not found")
+	}
+
+	eocd := tail[pos:]
+	dir := zipDirectory{
+		entries: int64(binary.LittleEndian.Uint16(eocd[10:])),
+		size:    int64(binary.LittleEndian.Uint32(eocd[12:])),
+		offset:  int64(binary.LittleEndian.Uint32(eocd[16:])),
+	}
+	if dir.entries != uint16Max && dir.size != uint32Max && dir.offset != uint32Max {
+		return dir, nil
+	}
+
+	locatorPos := pos - zip64LocatorLen
+	if locatorPos < 0 || binary.LittleEndian.Uint32(tail[locatorPos:]) != zip64LocatorSig {
+		return zipDirectory{}, fmt.Errorf("zip64 end of central directory locator not found")
+	}
+	recordOffset, err := zipInt64(binary.LittleEndian.Uint64(tail[locatorPos+8:]), "zip64 record offset")
+	if err != nil {
+		return zipDirectory{}, err
+	}
+	// The record precedes its locator
+	if recordOffset > tailStart+int64(locatorPos)-zip64EOCDLen {
+		return zipDirectory{}, fmt.Errorf("zip64 end of central directory record offset %d is past its locator", recordOffset)
+	}
+
+	var record []byte
+	if recordOffset >= tailStart {
+		record = tail[recordOffset-tailStart:]
+	} else {
+		fetched, err := p.fetchRange(ctx, fileUrl, recordOffset, recordOffset+zip64EOCDLen-1)
+		if err != nil {
+			return zipDirectory{}, err
+		}
+		record = fetched
+	}
+	return parseZip64EOCD(record)
+}
+
+// findZipEOCD returns the position of the last end-of-central-directory
+// signature in the tail, or -1.
+func findZipEOCD(tail []byte) int {
+	for i := len(tail) - zipEOCDLen; i >= 0; i-- {
+		if binary.LittleEndian.Uint32(tail[i:]) == zipEOCDSig {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseZip64EOCD reads the directory location from a ZIP64 end of central
+// directory record, rejecting values that do not fit an int64.
+func parseZip64EOCD(record []byte) (zipDirectory, error) {
+	if len(record) < zip64EOCDLen || binary.LittleEndian.Uint32(record) != zip64EOCDSig {
+		return zipDirectory{}, fmt.Errorf("invalid zip64 end of central directory record")
+	}
+	dir := zipDirectory{zip64: true}
+	var err error
+	if dir.entries, err = zipInt64(binary.LittleEndian.Uint64(record[32:]), "zip64 entry count"); err != nil {
+		return zipDirectory{}, err
+	}
+	if dir.size, err = zipInt64(binary.LittleEndian.Uint64(record[40:]), "zip64 central directory size"); err != nil {
+		return zipDirectory{}, err
+	}
+	if dir.offset, err = zipInt64(binary.LittleEndian.Uint64(record[48:]), "zip64 central directory offset"); err != nil {
+		return zipDirectory{}, err
+	}
+	return dir, nil
+}
+
+// zipInt64 converts a ZIP64 field to int64, rejecting values that overflow.
+func zipInt64(value uint64, field string) (int64, error) {
+	if value > math.MaxInt64 {
+		return 0, fmt.Errorf("%s %d is out of range", field, value)
+	}
+	return int64(value), nil
+}
+
+// zipSlice returns the size bytes of data starting at offset, or an error
+// when they are not all present.
+func zipSlice(data []byte, offset int64, size int64) ([]byte, error) {
+	if offset < 0 || size < 0 || offset > int64(len(data)) || size > int64(len(data))-offset {
+		return nil, fmt.Errorf("range %d+%d exceeds the %d bytes read", offset, size, len(data))
+	}
+	return data[offset : offset+size], nil
+}
+
+// parseZipCentralDirectory walks the central directory file headers and
+// accumulates entry names, sizes, compression methods and encryption flags.
+func parseZipCentralDirectory(directory []byte, zipInfo *model.ZipInfo) error {
+	methods := map[string]bool{}
+	var entries int64
+	for len(directory) >= zipCentralHeaderLen {
+		if binary.LittleEndian.Uint32(directory) != zipCentralHeaderSig {
+			return fmt.Errorf("invalid central directory header at entry %d", entries)
+		}
+		flags := binary.LittleEndian.Uint16(directory[8:])
+		method := binary.LittleEndian.Uint16(directory[10:])
+		compressed := uint64(binary.LittleEndian.Uint32(directory[20:]))
+		uncompressed := uint64(binary.LittleEndian.Uint32(directory[24:]))
+		nameLen := int(binary.LittleEndian.Uint16(directory[28:]))
+		extraLen := int(binary.LittleEndian.Uint16(directory[30:]))
+		commentLen := int(binary.LittleEndian.Uint16(directory[32:]))
+
+		recordLen := zipCentralHeaderLen + nameLen + extraLen + commentLen
+		if len(directory) < recordLen {
+			return fmt.Errorf("truncated central directory at entry %d", entries)
+		}
+		name := string(directory[zipCentralHeaderLen : zipCentralHeaderLen+nameLen])
+		extra := directory[zipCentralHeaderLen+nameLen : zipCentralHeaderLen+nameLen+extraLen]
+
+		if uncompressed == uint32Max || compressed == uint32Max {
+			zipInfo.Zip64 = true
+			uncompressed, compressed = readZip64Sizes(extra, uncompressed, compressed)
+		}
+		if uncompressed > math.MaxInt64-uint64(zipInfo.TotalUncompressedBytes) || compressed > math.MaxInt64-uint64(zipInfo.TotalCompressedBytes) {
+			return fmt.Errorf("entry sizes overflow at entry %d", entries)
+		}
+
+		if flags&zipEncryptedFlag != 0 || method == zipAESEncryptedMethod {
+			zipInfo.Encrypted = true
+		}
+		methodName, ok := zipMethodNames[method]
+		if !ok {
+			methodName = fmt.Sprintf("method-%d", method)
+		}
+		methods[methodName] = true
+
+		zipInfo.TotalUncompressedBytes += int64(uncompressed)
+		zipInfo.TotalCompressedBytes += int64(compressed)
+		if len(zipInfo.EntryNames) < constants.ZIP_MAX_ENTRY_NAMES {
+			zipInfo.EntryNames = append(zipInfo.EntryNames, name)
+		} else {
+			zipInfo.EntryNamesTruncated = true
+		}
+
+		entries++
+		directory = directory[recordLen:]
+	}
+
+	if entries > zipInfo.EntryCount {
+		zipInfo.EntryCount = entries
+	}
+	for method := range methods {
+		zipInfo.CompressionMethods = append(zipInfo.CompressionMethods, method)
+	}
+	sort.Strings(zipInfo.CompressionMethods)
+	return nil
+}
+
+// readZip64Sizes reads the ZIP64 extended information extra field. Only the
+// sizes that overflowed in the header are present, uncompressed first.
+func readZip64Sizes(extra []byte, uncompressed uint64, compressed uint64) (uint64, uint64) {
+	for len(extra) >= 4 {
+		id := binary.LittleEndian.Uint16(extra)
+		size := int(binary.LittleEndian.Uint16(extra[2:]))
+		if len(extra) < 4+size {
+			break
+		}
+		field := extra[4 : 4+size]
+		if id == zip64ExtraID {
+			if uncompressed == uint32Max && len(field) >= 8 {
+				uncompressed = binary.LittleEndian.Uint64(field)
+				field = field[8:]
+			}
+			if compressed == uint32Max && len(field) >= 8 {
+				compressed = binary.LittleEndian.Uint64(field)
+			}
+			break
+		}
+		extra = extra[4+size:]
+	}
+	return uncompressed, compressed
+}
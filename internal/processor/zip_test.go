@@ -0,0 +1,201 @@
+package processor
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"go.uber.org/zap"
+)
+
+// zipArchive builds an archive holding the given files.
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// zip64Tail builds a ZIP64 end of central directory record, its locator and
+// a classic record that defers to it, for an archive starting at prefix.
+func zip64Tail(prefix []byte, entries uint64, size uint64, offset uint64) []byte {
+	data := append([]byte{}, prefix...)
+	recordOffset := uint64(len(data))
+
+	record := make([]byte, zip64EOCDLen)
+	binary.LittleEndian.PutUint32(record, zip64EOCDSig)
+	binary.LittleEndian.PutUint64(record[32:], entries)
+	binary.LittleEndian.PutUint64(record[40:], size)
+	binary.LittleEndian.PutUint64(record[48:], offset)
+	data = append(data, record...)
+
+	locator := make([]byte, zip64LocatorLen)
+	binary.LittleEndian.PutUint32(locator, zip64LocatorSig)
+	binary.LittleEndian.PutUint64(locator[8:], recordOffset)
+	data = append(data, locator...)
+
+	eocd := make([]byte, zipEOCDLen)
+	binary.LittleEndian.PutUint32(eocd, zipEOCDSig)
+	binary.LittleEndian.PutUint16(eocd[10:], uint16Max)
+	binary.LittleEndian.PutUint32(eocd[12:], uint32Max)
+	binary.LittleEndian.PutUint32(eocd[16:], uint32Max)
+	return append(data, eocd...)
+}
+
+// classicTail builds a classic end of central directory record.
+func classicTail(prefix []byte, entries uint16, size uint32, offset uint32) []byte {
+	eocd := make([]byte, zipEOCDLen)
+	binary.LittleEndian.PutUint32(eocd, zipEOCDSig)
+	binary.LittleEndian.PutUint16(eocd[10:], entries)
+	binary.LittleEndian.PutUint32(eocd[12:], size)
+	binary.LittleEndian.PutUint32(eocd[16:], offset)
+	return append(append([]byte{}, prefix...), eocd...)
+}
+
+// serveBytes serves data with range support.
+func serveBytes(t *testing.T, data []byte) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "archive.zip", time.Time{}, bytes.NewReader(data))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestReadZipCentralDirectory(t *testing.T) {
+	valid := zipArchive(t, map[string]string{"a.csv": "1,2,3\n", "b.csv": strings.Repeat("x", 100)})
+
+	tests := []struct {
+		name     string
+		data     []byte
+		size     int64 // size reported for the archive, len(data) when 0
+		entries  int64
+		uncomp   int64
+		errorMsg string
+	}{
+		{name: "valid archive", data: valid, entries: 2, uncomp: 106},
+		{name: "empty directory", data: classicTail(nil, 0, 0, 0)},
+		{name: "no end of central directory", data: bytes.Repeat([]byte{0}, 64), errorMsg: "not found"},
+		{name: "directory past archive end", data: classicTail(make([]byte, 10), 1, 100, 5), errorMsg: "exceeds archive size"},
+		{name: "zip64 size overflows offset", data: zip64Tail(make([]byte, 10), 1, math.MaxInt64-5, 10), errorMsg: "exceeds archive size"},
+		{name: "zip64 size out of int64 range", data: zip64Tail(make([]byte, 10), 1, math.MaxUint64, 0), errorMsg: "out of range"},
+		{name: "zip64 offset out of int64 range", data: zip64Tail(make([]byte, 10), 1, 10, math.MaxUint64), errorMsg: "out of range"},
+		{name: "zip64 record missing", data: zip64Tail(make([]byte, 10), 1, 10, 0)[zip64EOCDLen:], errorMsg: "zip64"},
+		{name: "tail shorter than declared", data: classicTail(make([]byte, 10), 1, 40, 20), size: 90, errorMsg: "exceeds the"},
+		{name: "bad central directory header", data: classicTail(make([]byte, 60), 1, 60, 0), errorMsg: "invalid central directory header"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := serveBytes(t, tt.data)
+			size := tt.size
+			if size == 0 {
+				size = int64(len(tt.data))
+			}
+
+			p := &Processor{logger: zap.NewNop()}
+			info, err := p.readZipCentralDirectory(context.Background(), server.URL, size)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("error = %v, want %q", err, tt.errorMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.EntryCount != tt.entries || info.TotalUncompressedBytes != tt.uncomp {
+				t.Errorf("got %d entries of %d bytes, want %d of %d", info.EntryCount, info.TotalUncompressedBytes, tt.entries, tt.uncomp)
+			}
+		})
+	}
+}
+
+func TestParseZipCentralDirectory(t *testing.T) {
+	archive := zipArchive(t, map[string]string{"report.csv": "data"})
+	pos := findZipEOCD(archive)
+	size := binary.LittleEndian.Uint32(archive[pos+12:])
+	offset := binary.LittleEndian.Uint32(archive[pos+16:])
+	directory := archive[offset : offset+size]
+
+	oversized := append([]byte{}, directory...)
+	binary.LittleEndian.PutUint32(oversized[20:], uint32Max)
+	binary.LittleEndian.PutUint32(oversized[24:], uint32Max)
+
+	tests := []struct {
+		name      string
+		directory []byte
+		entries   int64
+		errorMsg  string
+	}{
+		{name: "valid", directory: directory, entries: 1},
+		{name: "empty", directory: nil},
+		{name: "truncated name", directory: directory[:zipCentralHeaderLen+2], errorMsg: "truncated"},
+		{name: "bad signature", directory: append([]byte{1, 2, 3, 4}, directory[4:]...), errorMsg: "invalid central directory header"},
+		{name: "zip64 sizes without extra field", directory: oversized, entries: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := &model.ZipInfo{}
+			err := parseZipCentralDirectory(tt.directory, info)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("error = %v, want %q", err, tt.errorMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.EntryCount != tt.entries {
+				t.Errorf("EntryCount = %d, want %d", info.EntryCount, tt.entries)
+			}
+		})
+	}
+}
+
+func TestZipSlice(t *testing.T) {
+	data := make([]byte, 10)
+	tests := []struct {
+		name   string
+		offset int64
+		size   int64
+		ok     bool
+	}{
+		{name: "whole", offset: 0, size: 10, ok: true},
+		{name: "suffix", offset: 9, size: 1, ok: true},
+		{name: "past end", offset: 9, size: 2},
+		{name: "offset past end", offset: 11, size: 0},
+		{name: "negative offset", offset: -1, size: 1},
+		{name: "negative size", offset: 0, size: -1},
+		{name: "overflowing size", offset: 5, size: math.MaxInt64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := zipSlice(data, tt.offset, tt.size)
+			if (err == nil) != tt.ok {
+				t.Fatalf("error = %v, want ok %v", err, tt.ok)
+			}
+			if tt.ok && int64(len(got)) != tt.size {
+				t.Errorf("len = %d, want %d", len(got), tt.size)
+			}
+		})
+	}
+}
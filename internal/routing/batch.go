@@ -0,0 +1,86 @@
+package routing
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+)
+
+// BatchSpec describes how a rule running on Cloud Batch sizes its job from
+// the file metadata. The disk must hold the file and its uncompressed output,
+// and the maximum runtime grows with the file at the expected throughput.
+type BatchSpec struct {
+	ImageUri       string            `json:"imageUri"`
+	MachineType    string            `json:"machineType,omitempty"`    // defaults to constants.BATCH_DEFAULT_MACHINE_TYPE
+	MachineTypes   map[string]string `json:"machineTypes,omitempty"`   // per size tier, overrides machineType
+	MinDiskGB      int64             `json:"minDiskGB,omitempty"`      // defaults to constants.BATCH_MIN_DISK_GB
+	DiskHeadroom   float64           `json:"diskHeadroom,omitempty"`   // multiple of the largest known size, defaults to 2
+	ThroughputMBps float64           `json:"throughputMBps,omitempty"` // defaults to constants.BATCH_THROUGHPUT_MBPS
+	MinRunMinutes  int64             `json:"minRunMinutes,omitempty"`  // defaults to constants.BATCH_MIN_RUN_MINUTES
+	MaxRunMinutes  int64             `json:"maxRunMinutes,omitempty"`  // defaults to constants.BATCH_MAX_RUN_MINUTES
+}
+
+// validate fills in defaults and checks the limits are consistent.
+func (s *BatchSpec) validate(ruleName string) error {
+	if s.ImageUri == "" {
+		return fmt.Errorf("routing rule %q batch spec is missing an imageUri", ruleName)
+	}
+	if s.MinDiskGB < 0 || s.DiskHeadroom < 0 || s.ThroughputMBps < 0 || s.MinRunMinutes < 0 || s.MaxRunMinutes < 0 {
+		return fmt.Errorf("routing rule %q batch spec limits must not be negative", ruleName)
+	}
+	if s.MachineType == "" {
+		s.MachineType = constants.BATCH_DEFAULT_MACHINE_TYPE
+	}
+	if s.MinDiskGB == 0 {
+		s.MinDiskGB = constants.BATCH_MIN_DISK_GB
+	}
+	if s.DiskHeadroom == 0 {
+		s.DiskHeadroom = 2
+	}
+	if s.ThroughputMBps == 0 {
+		s.ThroughputMBps = constants.BATCH_THROUGHPUT_MBPS
+	}
+	if s.MinRunMinutes == 0 {
+		s.MinRunMinutes = constants.BATCH_MIN_RUN_MINUTES
+	}
+	if s.MaxRunMinutes == 0 {
+		s.MaxRunMinutes = constants.BATCH_MAX_RUN_MINUTES
+	}
+	if s.MinRunMinutes > s.MaxRunMinutes {
+		return fmt.Errorf("routing rule %q batch spec minRunMinutes exceeds maxRunMinutes", ruleName)
+	}
+	return nil
+}
+
+// BatchResources derives the machine type, boot disk size and maximum runtime
+// of the rule's Cloud Batch job from the file. When the size is unknown the
+// minimum disk and the maximum runtime are used. A timeout set by the rule's
+// profile replaces the derived runtime.
+func (rule *Rule) BatchResources(info model.FileInfo) model.BatchResources {
+	spec := rule.Batch
+	resources := model.BatchResources{
+		ImageUri:          spec.ImageUri,
+		MachineType:       spec.MachineType,
+		DiskSizeGB:        spec.MinDiskGB,
+		MaxRunTimeMinutes: spec.MaxRunMinutes,
+	}
+	if machineType, ok := spec.MachineTypes[info.SizeTier]; ok {
+		resources.MachineType = machineType
+	}
+
+	if !info.SizeUnknown {
+		sizeBytes := float64(max(info.SizeBytes, info.UncompressedBytes))
+		diskGB := int64(math.Ceil(sizeBytes * spec.DiskHeadroom / constants.FILE_SIZE_BYTES))
+		resources.DiskSizeGB = max(spec.MinDiskGB, diskGB)
+
+		runMinutes := int64(math.Ceil(sizeBytes / (spec.ThroughputMBps * 1e6) / 60))
+		resources.MaxRunTimeMinutes = min(max(runMinutes, spec.MinRunMinutes), spec.MaxRunMinutes)
+	}
+
+	if rule.profile != nil && rule.profile.TimeoutSeconds > 0 {
+		resources.MaxRunTimeMinutes = (rule.profile.TimeoutSeconds + 59) / 60
+	}
+	return resources
+}
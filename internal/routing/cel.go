@@ -0,0 +1,92 @@
+package routing
+
+import (
+	"fmt"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/google/cel-go/cel"
+)
+
+// newEnv declares the model.FileInfo fields that rule expressions may reference.
+// Variable names follow the JSON field names of the response.
+func newEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.CrossTypeNumericComparisons(true),
+		cel.Variable("fileUrl", cel.StringType),
+		cel.Variable("fileName", cel.StringType),
+		cel.Variable("host", cel.StringType),
+		cel.Variable("fileExtension", cel.StringType),
+		cel.Variable("contentType", cel.StringType),
+		cel.Variable("fileSizeBytes", cel.IntType),
+		cel.Variable("rangeSupported", cel.BoolType),
+		cel.Variable("sizeTier", cel.StringType),
+		cel.Variable("sizeUnknown", cel.BoolType),
+		cel.Variable("detectedFormat", cel.StringType),
+		cel.Variable("layers", cel.ListType(cel.StringType)),
+		cel.Variable("payloadFormat", cel.StringType),
+		cel.Variable("uncompressedBytes", cel.IntType),
+		cel.Variable("zipEntryCount", cel.IntType),
+		cel.Variable("zipEncrypted", cel.BoolType),
+		cel.Variable("gzipSizeReliable", cel.BoolType),
+	)
+}
+
+// celVars builds the activation for evaluating an expression against a file.
+// Archive fields are left out when the archive was not inspected, so rules
+// referencing them do not match.
+func celVars(info model.FileInfo) map[string]any {
+	vars := map[string]any{
+		"fileUrl":        info.FIleUrl,
+		"fileName":       info.FileName,
+		"host":           info.Host,
+		"fileExtension":  info.FileExtension,
+		"contentType":    info.ContentType,
+		"fileSizeBytes":  info.SizeBytes,
+		"rangeSupported": info.RangeSupported,
+		"sizeTier":       info.SizeTier,
+		"sizeUnknown":    info.SizeUnknown,
+		"detectedFormat": info.DetectedFormat,
+		"layers":         info.Layers,
+		"payloadFormat":  info.PayloadFormat,
+	}
+	if uncompressed, ok := uncompressedBytes(info); ok {
+		vars["uncompressedBytes"] = uncompressed
+	}
+	if info.Zip != nil {
+		vars["zipEntryCount"] = info.Zip.EntryCount
+		vars["zipEncrypted"] = info.Zip.Encrypted
+	}
+	if info.Gzip != nil {
+		vars["gzipSizeReliable"] = info.Gzip.Reliable
+	}
+	return vars
+}
+
+// compileExpression parses and type-checks a rule expression, ensuring it
+// evaluates to a boolean.
+func compileExpression(env *cel.Env, ruleName string, expr string) (cel.Program, error) {
+	ast, iss := env.Compile(expr)
+	if iss.Err() != nil {
+		return nil, fmt.Errorf("routing rule %q has an invalid expression: %v", ruleName, iss.Err())
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("routing rule %q expression must return bool, got %s", ruleName, ast.OutputType())
+	}
+
+	prg, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("routing rule %q expression cannot be planned: %v", ruleName, err)
+	}
+	return prg, nil
+}
+
+// evalExpression runs a compiled expression. Evaluation errors, such as a
+// missing field, are treated as a non-match.
+func evalExpression(prg cel.Program, info model.FileInfo) bool {
+	out, _, err := prg.Eval(celVars(info))
+	if err != nil {
+		return false
+	}
+	matched, ok := out.Value().(bool)
+	return ok && matched
+}
@@ -0,0 +1,78 @@
+package routing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+)
+
+func TestMatchExpression(t *testing.T) {
+	router, err := New(Config{
+		Rules: []Rule{
+			{Name: "report", Job: "report", Match: Match{Expression: `fileName.startsWith("report-") && fileSizeBytes < 100`}},
+			{Name: "json-extension", Job: "json", Match: Match{Extensions: []string{".json"}, Expression: `host == "data.example.com"`}},
+			{Name: "layers", Job: "tar", Match: Match{Expression: `"tar" in layers && payloadFormat == "csv"`}},
+			{Name: "encrypted-zip", Job: "zip", Match: Match{Expression: `zipEncrypted`}},
+			{Name: "big-output", Job: "big", Match: Match{Expression: `uncompressedBytes > 1000`}},
+		},
+		Default: &Rule{Job: "fallback"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		info model.FileInfo
+		rule string
+	}{
+		{name: "expression true", info: model.FileInfo{FileName: "report-1.bin", SizeBytes: 50}, rule: "report"},
+		{name: "expression false", info: model.FileInfo{FileName: "report-1.bin", SizeBytes: 500}, rule: "default"},
+		{name: "expression and criteria", info: model.FileInfo{FileExtension: ".json", Host: "data.example.com"}, rule: "json-extension"},
+		{name: "criteria without expression", info: model.FileInfo{FileExtension: ".json", Host: "other.example.com"}, rule: "default"},
+		{name: "list variable", info: model.FileInfo{Layers: []string{"gzip", "tar"}, PayloadFormat: "csv"}, rule: "layers"},
+		{name: "uninspected archive", info: model.FileInfo{FileName: "a.zip"}, rule: "default"},
+		{name: "inspected archive", info: model.FileInfo{Zip: &model.ZipInfo{Encrypted: true}}, rule: "encrypted-zip"},
+		{name: "uncompressed size", info: model.FileInfo{Zip: &model.ZipInfo{}, UncompressedBytes: 5000}, rule: "big-output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, ok := router.Match(tt.info)
+			if !ok {
+				t.Fatal("no rule matched")
+			}
+			if rule.Name != tt.rule {
+				t.Errorf("matched %q, want %q", rule.Name, tt.rule)
+			}
+		})
+	}
+}
+
+func TestCompileExpression(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		errorMsg   string
+	}{
+		{name: "valid", expression: `fileSizeBytes > 10 && contentType.startsWith("text/")`},
+		{name: "syntax error", expression: "fileName ===", errorMsg: "invalid expression"},
+		{name: "unknown variable", expression: "owner == 'me'", errorMsg: "invalid expression"},
+		{name: "type error", expression: "fileName > 1", errorMsg: "invalid expression"},
+		{name: "non-bool result", expression: "fileSizeBytes + 1", errorMsg: "must return bool"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(Config{Rules: []Rule{{Name: "r", Job: "job", Match: Match{Expression: tt.expression}}}})
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Fatalf("error = %v, want %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package routing
+
+import (
+	"fmt"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+)
+
+// DispatchPolicy bounds how the dispatcher launches queued contract files:
+// how many executions it keeps running per job and how many queued rows it
+// reads per run.
+type DispatchPolicy struct {
+	MaxRunning    int            `json:"maxRunning,omitempty"`    // per job, defaults to constants.DEFAULT_DISPATCH_MAX_RUNNING
+	JobMaxRunning map[string]int `json:"jobMaxRunning,omitempty"` // per job name, overrides maxRunning
+	BatchSize     int            `json:"batchSize,omitempty"`     // defaults to constants.DEFAULT_DISPATCH_BATCH_SIZE
+}
+
+// validate fills in defaults and checks the limits are positive.
+func (d *DispatchPolicy) validate() error {
+	if d.MaxRunning < 0 || d.BatchSize < 0 {
+		return fmt.Errorf("dispatch policy values must not be negative")
+	}
+	for jobName, maxRunning := range d.JobMaxRunning {
+		if maxRunning <= 0 {
+			return fmt.Errorf("dispatch policy maxRunning for job %q must be positive", jobName)
+		}
+	}
+	if d.MaxRunning == 0 {
+		d.MaxRunning = constants.DEFAULT_DISPATCH_MAX_RUNNING
+	}
+	if d.BatchSize == 0 {
+		d.BatchSize = constants.DEFAULT_DISPATCH_BATCH_SIZE
+	}
+	return nil
+}
+
+// validateDispatch checks the rule's dispatch mode and fills in the default.
+func (rule *Rule) validateDispatch() error {
+	switch rule.Dispatch {
+	case "":
+		rule.Dispatch = constants.DISPATCH_IMMEDIATE
+	case constants.DISPATCH_IMMEDIATE:
+	case constants.DISPATCH_DEFERRED:
+		if !rule.Queue {
+			return fmt.Errorf("routing rule %q defers dispatch and must set queue", rule.Name)
+		}
+	default:
+		return fmt.Errorf("routing rule %q has an unknown dispatch mode %q", rule.Name, rule.Dispatch)
+	}
+	return nil
+}
+
+// MaxRunning returns how many executions of the job the dispatcher keeps
+// running at once.
+func (r *Router) MaxRunning(jobName string) int {
+	if maxRunning, ok := r.dispatch.JobMaxRunning[jobName]; ok {
+		return maxRunning
+	}
+	return r.dispatch.MaxRunning
+}
+
+// DispatchBatchSize returns how many queued rows the dispatcher reads per run.
+func (r *Router) DispatchBatchSize() int {
+	return r.dispatch.BatchSize
+}
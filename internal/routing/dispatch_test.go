@@ -0,0 +1,96 @@
+package routing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+)
+
+func TestValidateDispatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     Rule
+		dispatch string
+		errorMsg string
+	}{
+		{name: "default", rule: Rule{Name: "r", Job: "job", Queue: true}, dispatch: constants.DISPATCH_IMMEDIATE},
+		{name: "deferred", rule: Rule{Name: "r", Job: "job", Queue: true, Dispatch: "deferred"}, dispatch: constants.DISPATCH_DEFERRED},
+		{name: "deferred without queue", rule: Rule{Name: "r", Job: "job", Dispatch: "deferred"}, errorMsg: "must set queue"},
+		{name: "unknown mode", rule: Rule{Name: "r", Job: "job", Queue: true, Dispatch: "later"}, errorMsg: "unknown dispatch mode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, err := New(Config{Rules: []Rule{tt.rule}})
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("error = %v, want %q", err, tt.errorMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			rule, _ := router.Match(model.FileInfo{})
+			if rule.Dispatch != tt.dispatch {
+				t.Errorf("dispatch = %q, want %q", rule.Dispatch, tt.dispatch)
+			}
+		})
+	}
+}
+
+func TestDispatchPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     DispatchPolicy
+		job        string
+		maxRunning int
+		batchSize  int
+		errorMsg   string
+	}{
+		{name: "defaults", job: "a", maxRunning: constants.DEFAULT_DISPATCH_MAX_RUNNING, batchSize: constants.DEFAULT_DISPATCH_BATCH_SIZE},
+		{name: "per job override", policy: DispatchPolicy{MaxRunning: 4, JobMaxRunning: map[string]int{"a": 1}}, job: "a", maxRunning: 1, batchSize: constants.DEFAULT_DISPATCH_BATCH_SIZE},
+		{name: "other job", policy: DispatchPolicy{MaxRunning: 4, JobMaxRunning: map[string]int{"a": 1}, BatchSize: 7}, job: "b", maxRunning: 4, batchSize: 7},
+		{name: "negative", policy: DispatchPolicy{MaxRunning: -1}, errorMsg: "must not be negative"},
+		{name: "zero job limit", policy: DispatchPolicy{JobMaxRunning: map[string]int{"a": 0}}, errorMsg: "must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, err := New(Config{Dispatch: tt.policy})
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("error = %v, want %q", err, tt.errorMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := router.MaxRunning(tt.job); got != tt.maxRunning {
+				t.Errorf("MaxRunning = %d, want %d", got, tt.maxRunning)
+			}
+			if got := router.DispatchBatchSize(); got != tt.batchSize {
+				t.Errorf("DispatchBatchSize = %d, want %d", got, tt.batchSize)
+			}
+		})
+	}
+}
+
+func TestBuiltInZipRulesQueueImmediately(t *testing.T) {
+	router, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tier := range []string{"small", "large"} {
+		t.Run(tier, func(t *testing.T) {
+			rule, ok := router.Match(model.FileInfo{FileExtension: ".zip", SizeTier: tier})
+			if !ok {
+				t.Fatal("no rule matched")
+			}
+			if !rule.Queue || rule.Dispatch != constants.DISPATCH_IMMEDIATE {
+				t.Errorf("rule %q queue %v dispatch %q, want queued and immediate", rule.Name, rule.Queue, rule.Dispatch)
+			}
+		})
+	}
+}
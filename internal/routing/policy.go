@@ -0,0 +1,110 @@
+package routing
+
+import (
+	"fmt"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+)
+
+// SizeTier names a band of file sizes. Tiers are evaluated in order and a file
+// belongs to the first tier whose MaxSizeGB it is below; a zero MaxSizeGB
+// marks the open-ended last tier.
+type SizeTier struct {
+	Name      string  `json:"name"`
+	MaxSizeGB float64 `json:"maxSizeGB,omitempty"`
+}
+
+// SizePolicy caps the accepted file size and classifies files into tiers that
+// rules can match on.
+type SizePolicy struct {
+	MaxFileSizeGB         float64    `json:"maxFileSizeGB,omitempty"`         // defaults to constants.MAX_FILE_SIZE
+	MaxUncompressedSizeGB float64    `json:"maxUncompressedSizeGB,omitempty"` // 0 disables the check
+	MaxCompressionRatio   float64    `json:"maxCompressionRatio,omitempty"`   // 0 disables the check
+	Tiers                 []SizeTier `json:"tiers,omitempty"`
+}
+
+// validate fills in defaults and checks the tiers are named and ascending.
+func (sp *SizePolicy) validate() error {
+	if sp.MaxFileSizeGB < 0 || sp.MaxUncompressedSizeGB < 0 || sp.MaxCompressionRatio < 0 {
+		return fmt.Errorf("size policy limits must not be negative")
+	}
+	if sp.MaxFileSizeGB == 0 {
+		sp.MaxFileSizeGB = constants.MAX_FILE_SIZE
+	}
+
+	previous := 0.0
+	for i, tier := range sp.Tiers {
+		if tier.Name == "" {
+			return fmt.Errorf("size tier %d is missing a name", i)
+		}
+		if tier.MaxSizeGB == 0 {
+			if i != len(sp.Tiers)-1 {
+				return fmt.Errorf("size tier %q without maxSizeGB must be the last tier", tier.Name)
+			}
+			continue
+		}
+		if tier.MaxSizeGB <= previous {
+			return fmt.Errorf("size tier %q must have a larger maxSizeGB than the previous tier", tier.Name)
+		}
+		previous = tier.MaxSizeGB
+	}
+	return nil
+}
+
+// CheckSize returns the reason the file violates the size policy, if any.
+// Besides the file size itself, archives whose inspected uncompressed size or
+// compression ratio exceed the limits are rejected as likely zip bombs.
+func (r *Router) CheckSize(info model.FileInfo) (string, bool) {
+	sp := r.sizePolicy
+	if !info.SizeUnknown && info.FileSizeFloat > sp.MaxFileSizeGB {
+		return fmt.Sprintf("file size %s exceeds the limit of %.2f GB", info.FileSize, sp.MaxFileSizeGB), true
+	}
+
+	uncompressed, ok := uncompressedBytes(info)
+	if !ok {
+		return "", false
+	}
+	uncompressedGB := float64(uncompressed) / constants.FILE_SIZE_BYTES
+	if sp.MaxUncompressedSizeGB > 0 && uncompressedGB > sp.MaxUncompressedSizeGB {
+		return fmt.Sprintf("uncompressed size %.2f GB exceeds the limit of %.2f GB", uncompressedGB, sp.MaxUncompressedSizeGB), true
+	}
+	if sp.MaxCompressionRatio > 0 && info.SizeBytes > 0 {
+		ratio := float64(uncompressed) / float64(info.SizeBytes)
+		if ratio > sp.MaxCompressionRatio {
+			return fmt.Sprintf("compression ratio %.1f exceeds the limit of %.1f", ratio, sp.MaxCompressionRatio), true
+		}
+	}
+	return "", false
+}
+
+// uncompressedBytes returns the expected uncompressed size of the file when
+// archive inspection produced one that can be trusted. Gzip estimates flagged
+// as unreliable are only lower bounds and are not used.
+func uncompressedBytes(info model.FileInfo) (int64, bool) {
+	if info.Zip == nil && info.Gzip == nil {
+		return 0, false
+	}
+	if info.Gzip != nil && !info.Gzip.Reliable {
+		return 0, false
+	}
+	if info.UncompressedBytes <= 0 {
+		return 0, false
+	}
+	return info.UncompressedBytes, true
+}
+
+// SizeTier returns the name of the tier the file falls into, or an empty
+// string when no tier applies. Files whose size could not be determined are
+// placed in the "unknown" tier.
+func (r *Router) SizeTier(info model.FileInfo) string {
+	if info.SizeUnknown {
+		return constants.SIZE_TIER_UNKNOWN
+	}
+	for _, tier := range r.sizePolicy.Tiers {
+		if tier.MaxSizeGB == 0 || info.FileSizeFloat < tier.MaxSizeGB {
+			return tier.Name
+		}
+	}
+	return ""
+}
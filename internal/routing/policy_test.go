@@ -0,0 +1,193 @@
+package routing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+)
+
+func TestSizeTier(t *testing.T) {
+	router, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		info model.FileInfo
+		tier string
+	}{
+		{name: "empty", info: model.FileInfo{FileSizeFloat: 0}, tier: "small"},
+		{name: "below first bound", info: model.FileInfo{FileSizeFloat: 0.99}, tier: "small"},
+		{name: "bound is exclusive", info: model.FileInfo{FileSizeFloat: 1}, tier: "medium"},
+		{name: "middle", info: model.FileInfo{FileSizeFloat: 9.5}, tier: "medium"},
+		{name: "open-ended last tier", info: model.FileInfo{FileSizeFloat: 500}, tier: "large"},
+		{name: "unknown size", info: model.FileInfo{SizeUnknown: true}, tier: constants.SIZE_TIER_UNKNOWN},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tier := router.SizeTier(tt.info); tier != tt.tier {
+				t.Errorf("tier = %q, want %q", tier, tt.tier)
+			}
+		})
+	}
+}
+
+func TestSizeTierWithoutOpenEndedTier(t *testing.T) {
+	router, err := New(Config{SizePolicy: SizePolicy{Tiers: []SizeTier{{Name: "small", MaxSizeGB: 1}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tier := router.SizeTier(model.FileInfo{FileSizeFloat: 2}); tier != "" {
+		t.Errorf("tier = %q, want none", tier)
+	}
+}
+
+func TestCheckSize(t *testing.T) {
+	router, err := New(Config{SizePolicy: SizePolicy{
+		MaxFileSizeGB:         10,
+		MaxUncompressedSizeGB: 20,
+		MaxCompressionRatio:   100,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gb := int64(constants.FILE_SIZE_BYTES)
+
+	tests := []struct {
+		name     string
+		info     model.FileInfo
+		rejected string
+	}{
+		{name: "within limits", info: model.FileInfo{FileSizeFloat: 5, SizeBytes: 5 * gb}},
+		{name: "too large", info: model.FileInfo{FileSize: "11.00", FileSizeFloat: 11}, rejected: "exceeds the limit of 10.00 GB"},
+		{name: "unknown size is not rejected", info: model.FileInfo{SizeUnknown: true, FileSizeFloat: 11}},
+		{
+			name:     "uncompressed too large",
+			info:     model.FileInfo{FileSizeFloat: 1, SizeBytes: gb, Gzip: &model.GzipInfo{Reliable: true}, UncompressedBytes: 21 * gb},
+			rejected: "uncompressed size",
+		},
+		{
+			name: "unreliable gzip estimate ignored",
+			info: model.FileInfo{FileSizeFloat: 1, SizeBytes: gb, Gzip: &model.GzipInfo{}, UncompressedBytes: 210 * gb},
+		},
+		{
+			name: "zero uncompressed size ignored",
+			info: model.FileInfo{SizeBytes: 1 << 20, Zip: &model.ZipInfo{}},
+		},
+		{
+			name:     "zip bomb ratio",
+			info:     model.FileInfo{SizeBytes: 1 << 20, Zip: &model.ZipInfo{}, UncompressedBytes: 200 << 20},
+			rejected: "compression ratio",
+		},
+		{
+			name: "uncompressed size ignored without inspection",
+			info: model.FileInfo{SizeBytes: 1 << 20, UncompressedBytes: 200 << 20},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, rejected := router.CheckSize(tt.info)
+			if rejected != (tt.rejected != "") {
+				t.Fatalf("rejected = %v (%q), want %v", rejected, reason, tt.rejected != "")
+			}
+			if rejected && !strings.Contains(reason, tt.rejected) {
+				t.Errorf("reason = %q, want %q", reason, tt.rejected)
+			}
+		})
+	}
+}
+
+func TestSizePolicyValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   SizePolicy
+		errorMsg string
+	}{
+		{name: "negative limit", policy: SizePolicy{MaxFileSizeGB: -1}, errorMsg: "negative"},
+		{name: "unnamed tier", policy: SizePolicy{Tiers: []SizeTier{{MaxSizeGB: 1}}}, errorMsg: "missing a name"},
+		{name: "open tier not last", policy: SizePolicy{Tiers: []SizeTier{{Name: "a"}, {Name: "b", MaxSizeGB: 1}}}, errorMsg: "must be the last tier"},
+		{name: "descending tiers", policy: SizePolicy{Tiers: []SizeTier{{Name: "a", MaxSizeGB: 2}, {Name: "b", MaxSizeGB: 1}}}, errorMsg: "larger maxSizeGB"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(Config{SizePolicy: tt.policy})
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Fatalf("error = %v, want %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestDefaultMaxFileSize(t *testing.T) {
+	router, err := New(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, rejected := router.CheckSize(model.FileInfo{FileSizeFloat: constants.MAX_FILE_SIZE + 1}); !rejected {
+		t.Error("files above the default maximum must be rejected")
+	}
+}
+
+func TestMatchSizeTier(t *testing.T) {
+	router, err := New(Config{
+		SizePolicy: SizePolicy{Tiers: []SizeTier{{Name: "small", MaxSizeGB: 1}, {Name: "large"}}},
+		Rules: []Rule{
+			{Name: "large-files", Job: "large", Match: Match{SizeTiers: []string{"large"}}},
+			{Name: "any", Job: "any"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		tier string
+		rule string
+	}{
+		{tier: "large", rule: "large-files"},
+		{tier: "small", rule: "any"},
+		{tier: constants.SIZE_TIER_UNKNOWN, rule: "any"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tier, func(t *testing.T) {
+			rule, _ := router.Match(model.FileInfo{SizeTier: tt.tier})
+			if rule.Name != tt.rule {
+				t.Errorf("matched %q, want %q", rule.Name, tt.rule)
+			}
+		})
+	}
+}
+
+func TestBuiltInRulesBySizeTier(t *testing.T) {
+	router, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		extension string
+		sizeGB    float64
+		rule      string
+		profile   string
+	}{
+		{name: "small gz", extension: ".gz", sizeGB: 0.5, rule: "gz-streamer"},
+		{name: "medium gz", extension: ".gz", sizeGB: 5, rule: "gz-streamer"},
+		{name: "large gz", extension: ".gz", sizeGB: 20, rule: "gz-streamer-large", profile: "large-file"},
+		{name: "small zip", extension: ".zip", sizeGB: 0.5, rule: "zip-downloader"},
+		{name: "large zip", extension: ".zip", sizeGB: 20, rule: "zip-downloader-large", profile: "large-file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := model.FileInfo{FileExtension: tt.extension, FileSizeFloat: tt.sizeGB}
+			info.SizeTier = router.SizeTier(info)
+			rule, ok := router.Match(info)
+			if !ok {
+				t.Fatal("no rule matched")
+			}
+			if rule.Name != tt.rule || rule.Profile != tt.profile {
+				t.Errorf("matched %q with profile %q, want %q with %q", rule.Name, rule.Profile, tt.rule, tt.profile)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package routing
+
+import (
+	"fmt"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+)
+
+// validateProfile checks the profile values are usable on the rule's backend.
+// Cloud Run job executions only accept env, timeout and task count overrides.
+func validateProfile(ruleName string, backend string, profile *model.ResourceProfile) error {
+	if profile.TimeoutSeconds < 0 || profile.TaskCount < 0 || profile.Parallelism < 0 || profile.CpuMilli < 0 || profile.MemoryMib < 0 {
+		return fmt.Errorf("routing rule %q profile values must not be negative", ruleName)
+	}
+	if backend == constants.BACKEND_CLOUD_RUN && (profile.Parallelism > 0 || profile.CpuMilli > 0 || profile.MemoryMib > 0) {
+		return fmt.Errorf("routing rule %q profile sets parallelism, cpuMilli or memoryMib, which Cloud Run cannot override per execution", ruleName)
+	}
+	if profile.Parallelism > 0 && profile.TaskCount > 0 && profile.Parallelism > profile.TaskCount {
+		return fmt.Errorf("routing rule %q profile parallelism exceeds its task count", ruleName)
+	}
+	return nil
+}
+
+// resolveProfile attaches the named profile to the rule.
+func (rule *Rule) resolveProfile(profiles map[string]model.ResourceProfile) error {
+	rule.profile = nil
+	if rule.Profile == "" {
+		return nil
+	}
+	profile, ok := profiles[rule.Profile]
+	if !ok {
+		return fmt.Errorf("routing rule %q references unknown profile %q", rule.Name, rule.Profile)
+	}
+	if err := validateProfile(rule.Name, rule.Backend, &profile); err != nil {
+		return err
+	}
+	rule.profile = &profile
+	return nil
+}
+
+// ResourceProfile returns the rule's resolved profile, or nil when the rule
+// does not select one.
+func (rule *Rule) ResourceProfile() *model.ResourceProfile {
+	return rule.profile
+}
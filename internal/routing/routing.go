@@ -0,0 +1,340 @@
+// Package routing evaluates declarative routing rules against analyzed file
+// metadata. Each rule matches on extension, content type, size range, host or
+// a CEL expression and names the compute job (plus its argument template) that
+// should process the file. A size policy rejects oversized files and assigns
+// each file a size tier that rules can match on. Rules are evaluated in order
+// and an optional default rule is used when nothing else matches.
+package routing
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+	"github.com/google/cel-go/cel"
+)
+
+//go:embed rules.json
+var defaultRules []byte
+
+// Match holds the conditions a file must satisfy for a rule to apply.
+// Empty conditions are ignored; all non-empty conditions must match.
+type Match struct {
+	Extensions   []string `json:"extensions,omitempty"`   // e.g. ".gz", matched case-insensitively
+	ContentTypes []string `json:"contentTypes,omitempty"` // media types, "application/*" is allowed
+	MinSizeBytes *int64   `json:"minSizeBytes,omitempty"` // inclusive lower bound
+	MaxSizeBytes *int64   `json:"maxSizeBytes,omitempty"` // exclusive upper bound
+	Hosts        []string `json:"hosts,omitempty"`        // exact host or "*.example.com"
+	SizeTiers    []string `json:"sizeTiers,omitempty"`    // size tier names from the size policy
+	Formats      []string `json:"formats,omitempty"`      // detected formats, e.g. "gzip"
+	Layers       []string `json:"layers,omitempty"`       // layers that must all be present, e.g. "tar"
+	Payloads     []string `json:"payloads,omitempty"`     // inner payload formats, e.g. "json"
+	Expression   string   `json:"expression,omitempty"`   // CEL expression over model.FileInfo
+
+	program cel.Program
+}
+
+// Rule maps matching files to a target job and its argument template. Jobs
+// run on Cloud Run unless the rule selects the Cloud Batch backend, in which
+// case Job names the batch job prefix and Batch sizes the job.
+type Rule struct {
+	Name     string     `json:"name"`
+	Match    Match      `json:"match"`
+	Job      string     `json:"job"`
+	Args     []string   `json:"args"`               // text/template strings rendered against model.FileInfo
+	Backend  string     `json:"backend,omitempty"`  // "cloud-run" (default) or "batch"
+	Batch    *BatchSpec `json:"batch,omitempty"`    // required by the batch backend
+	Profile  string     `json:"profile,omitempty"`  // name of a resource profile
+	Shard    *ShardSpec `json:"shard,omitempty"`    // split range-capable files into task shards
+	Payload  string     `json:"payload,omitempty"`  // "positional" (default), "arg" or "env"
+	Queue    bool       `json:"queue,omitempty"`    // record the file in the contract file queue
+	Dispatch string     `json:"dispatch,omitempty"` // "immediate" (default) or "deferred" to the dispatcher
+	Priority int64      `json:"priority,omitempty"` // queued files with a higher priority are dispatched first
+
+	args    []*template.Template
+	profile *model.ResourceProfile
+}
+
+// Config is the on-disk representation of the routing rules file.
+type Config struct {
+	SizePolicy SizePolicy                       `json:"sizePolicy"`
+	Profiles   map[string]model.ResourceProfile `json:"profiles,omitempty"`
+	Dispatch   DispatchPolicy                   `json:"dispatch"`
+	Rules      []Rule                           `json:"rules"`
+	Default    *Rule                            `json:"default,omitempty"`
+}
+
+// Router holds the compiled rules and evaluates them in order.
+type Router struct {
+	sizePolicy SizePolicy
+	dispatch   DispatchPolicy
+	rules      []*Rule
+	def        *Rule
+}
+
+// Load reads the routing rules file at the given path. When path is empty
+// the built-in rules shipped with the service are used.
+func Load(path string) (*Router, error) {
+	data := defaultRules
+	if path != "" {
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read routing rules %s: %v", path, err)
+		}
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid routing rules format: %v", err)
+	}
+	return New(cfg)
+}
+
+// New validates the configuration and compiles every argument template and
+// expression, so a bad rule is reported before any file is routed.
+func New(cfg Config) (*Router, error) {
+	env, err := newEnv()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create expression environment: %v", err)
+	}
+
+	if err := cfg.SizePolicy.validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Dispatch.validate(); err != nil {
+		return nil, err
+	}
+
+	r := &Router{sizePolicy: cfg.SizePolicy, dispatch: cfg.Dispatch}
+	for i := range cfg.Rules {
+		rule := cfg.Rules[i]
+		if err := rule.compile(env); err != nil {
+			return nil, err
+		}
+		if err := rule.resolveProfile(cfg.Profiles); err != nil {
+			return nil, err
+		}
+		r.rules = append(r.rules, &rule)
+	}
+
+	if cfg.Default != nil {
+		def := *cfg.Default
+		if def.Name == "" {
+			def.Name = "default"
+		}
+		if err := def.compile(env); err != nil {
+			return nil, err
+		}
+		if err := def.resolveProfile(cfg.Profiles); err != nil {
+			return nil, err
+		}
+		r.def = &def
+	}
+	return r, nil
+}
+
+// Match returns the first rule matching the file, falling back to the default
+// rule. The boolean is false when no rule applies.
+func (r *Router) Match(info model.FileInfo) (*Rule, bool) {
+	for _, rule := range r.rules {
+		if rule.Match.matches(info) {
+			return rule, true
+		}
+	}
+	if r.def != nil {
+		return r.def, true
+	}
+	return nil, false
+}
+
+// UsesBackend reports whether any rule runs its jobs on the named backend.
+func (r *Router) UsesBackend(backend string) bool {
+	for _, rule := range r.rules {
+		if rule.Backend == backend {
+			return true
+		}
+	}
+	return r.def != nil && r.def.Backend == backend
+}
+
+// RenderArgs renders the rule's argument templates for the given file.
+func (rule *Rule) RenderArgs(info model.FileInfo) ([]string, error) {
+	args := make([]string, 0, len(rule.args))
+	for _, tmpl := range rule.args {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, info); err != nil {
+			return nil, fmt.Errorf("unable to render args for rule %q: %v", rule.Name, err)
+		}
+		args = append(args, buf.String())
+	}
+	return args, nil
+}
+
+// compile validates the rule and parses its argument templates and expression.
+func (rule *Rule) compile(env *cel.Env) error {
+	if rule.Name == "" {
+		return fmt.Errorf("routing rule is missing a name")
+	}
+	if rule.Job == "" {
+		return fmt.Errorf("routing rule %q is missing a job", rule.Name)
+	}
+
+	switch rule.Backend {
+	case "":
+		rule.Backend = constants.BACKEND_CLOUD_RUN
+	case constants.BACKEND_CLOUD_RUN:
+	case constants.BACKEND_BATCH:
+		if rule.Batch == nil {
+			return fmt.Errorf("routing rule %q uses the batch backend without a batch spec", rule.Name)
+		}
+		if err := rule.Batch.validate(rule.Name); err != nil {
+			return err
+		}
+		// Batch jobs are not tracked, so a queued entry would hold its
+		// job's slot until the running window passes
+		if rule.Queue {
+			return fmt.Errorf("routing rule %q cannot queue files on the batch backend", rule.Name)
+		}
+	default:
+		return fmt.Errorf("routing rule %q has an unknown backend %q", rule.Name, rule.Backend)
+	}
+
+	switch rule.Payload {
+	case "":
+		rule.Payload = constants.PAYLOAD_POSITIONAL
+	case constants.PAYLOAD_POSITIONAL:
+	case constants.PAYLOAD_ARG, constants.PAYLOAD_ENV:
+		if len(rule.Args) > 0 {
+			return fmt.Errorf("routing rule %q sends a %s payload and must not set args", rule.Name, rule.Payload)
+		}
+	default:
+		return fmt.Errorf("routing rule %q has an unknown payload mode %q", rule.Name, rule.Payload)
+	}
+
+	if err := rule.validateDispatch(); err != nil {
+		return err
+	}
+
+	if rule.Shard != nil {
+		if rule.Backend != constants.BACKEND_CLOUD_RUN {
+			return fmt.Errorf("routing rule %q can only shard on the cloud-run backend", rule.Name)
+		}
+		if err := rule.Shard.validate(rule.Name); err != nil {
+			return err
+		}
+	}
+
+	rule.args = nil
+	for i, arg := range rule.Args {
+		tmpl, err := template.New(fmt.Sprintf("%s.args[%d]", rule.Name, i)).Option("missingkey=error").Parse(arg)
+		if err != nil {
+			return fmt.Errorf("routing rule %q has an invalid argument template: %v", rule.Name, err)
+		}
+		rule.args = append(rule.args, tmpl)
+	}
+
+	rule.Match.program = nil
+	if rule.Match.Expression != "" {
+		prg, err := compileExpression(env, rule.Name, rule.Match.Expression)
+		if err != nil {
+			return err
+		}
+		rule.Match.program = prg
+	}
+	return nil
+}
+
+// matches reports whether every configured condition holds for the file.
+func (m Match) matches(info model.FileInfo) bool {
+	if len(m.Extensions) > 0 && !containsFold(m.Extensions, info.FileExtension) {
+		return false
+	}
+	if len(m.ContentTypes) > 0 && !matchContentType(m.ContentTypes, info.ContentType) {
+		return false
+	}
+	if m.MinSizeBytes != nil && info.SizeBytes < *m.MinSizeBytes {
+		return false
+	}
+	if m.MaxSizeBytes != nil && info.SizeBytes >= *m.MaxSizeBytes {
+		return false
+	}
+	if len(m.Hosts) > 0 && !matchHost(m.Hosts, info.Host) {
+		return false
+	}
+	if len(m.SizeTiers) > 0 && !containsFold(m.SizeTiers, info.SizeTier) {
+		return false
+	}
+	if len(m.Formats) > 0 && !containsFold(m.Formats, info.DetectedFormat) {
+		return false
+	}
+	for _, layer := range m.Layers {
+		if !containsFold(info.Layers, layer) {
+			return false
+		}
+	}
+	if len(m.Payloads) > 0 && !containsFold(m.Payloads, info.PayloadFormat) {
+		return false
+	}
+	if m.program != nil && !evalExpression(m.program, info) {
+		return false
+	}
+	return true
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchContentType compares media types, ignoring parameters such as charset.
+// A pattern ending in "/*" matches any subtype.
+func matchContentType(patterns []string, contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+			continue
+		}
+		if mediaType == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// matchHost compares hosts case-insensitively. A pattern starting with "*."
+// matches the domain itself and any of its subdomains.
+func matchHost(patterns []string, host string) bool {
+	host = strings.ToLower(host)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if base, ok := strings.CutPrefix(pattern, "*."); ok {
+			if host == base || strings.HasSuffix(host, "."+base) {
+				return true
+			}
+			continue
+		}
+		if host == pattern {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package routing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+)
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func TestMatch(t *testing.T) {
+	router, err := New(Config{
+		Rules: []Rule{
+			{Name: "big-json", Job: "big", Match: Match{Extensions: []string{".json"}, MinSizeBytes: int64Ptr(1000)}},
+			{Name: "json", Job: "json", Match: Match{Extensions: []string{".JSON"}}},
+			{Name: "csv-type", Job: "csv", Match: Match{ContentTypes: []string{"text/*"}}},
+			{Name: "partner", Job: "partner", Match: Match{Hosts: []string{"*.partner.example"}}},
+			{Name: "no-dot-wildcard", Job: "vendor", Match: Match{Hosts: []string{"*vendor.example"}}},
+			{Name: "tar-json", Job: "tar", Match: Match{Layers: []string{"tar"}, Payloads: []string{"json"}}},
+		},
+		Default: &Rule{Job: "fallback"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		info model.FileInfo
+		rule string
+	}{
+		{name: "first matching rule wins", info: model.FileInfo{FileExtension: ".json", SizeBytes: 5000}, rule: "big-json"},
+		{name: "size bound excludes", info: model.FileInfo{FileExtension: ".json", SizeBytes: 10}, rule: "json"},
+		{name: "extension case-insensitive", info: model.FileInfo{FileExtension: ".Json"}, rule: "json"},
+		{name: "content type wildcard with params", info: model.FileInfo{ContentType: "text/csv; charset=utf-8"}, rule: "csv-type"},
+		{name: "host wildcard", info: model.FileInfo{Host: "cdn.Partner.example"}, rule: "partner"},
+		{name: "host wildcard matches apex", info: model.FileInfo{Host: "partner.example"}, rule: "partner"},
+		{name: "host wildcard needs label boundary", info: model.FileInfo{Host: "notpartner.example"}, rule: "default"},
+		{name: "wildcard without dot is literal", info: model.FileInfo{Host: "badvendor.example"}, rule: "default"},
+		{name: "layers and payload", info: model.FileInfo{Layers: []string{"gzip", "tar"}, PayloadFormat: "json"}, rule: "tar-json"},
+		{name: "layers missing one", info: model.FileInfo{Layers: []string{"gzip"}, PayloadFormat: "json"}, rule: "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, ok := router.Match(tt.info)
+			if !ok {
+				t.Fatal("no rule matched")
+			}
+			if rule.Name != tt.rule {
+				t.Errorf("matched %q, want %q", rule.Name, tt.rule)
+			}
+		})
+	}
+}
+
+func TestMatchWithoutDefault(t *testing.T) {
+	router, err := New(Config{Rules: []Rule{{Name: "json", Job: "json", Match: Match{Extensions: []string{".json"}}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule, ok := router.Match(model.FileInfo{FileExtension: ".csv"}); ok {
+		t.Errorf("matched %q, want no rule", rule.Name)
+	}
+}
+
+func TestNewRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     Rule
+		errorMsg string
+	}{
+		{name: "missing name", rule: Rule{Job: "job"}, errorMsg: "missing a name"},
+		{name: "missing job", rule: Rule{Name: "r"}, errorMsg: "missing a job"},
+		{name: "unknown backend", rule: Rule{Name: "r", Job: "job", Backend: "lambda"}, errorMsg: "unknown backend"},
+		{name: "batch without spec", rule: Rule{Name: "r", Job: "job", Backend: "batch"}, errorMsg: "batch spec"},
+		{name: "queued batch", rule: Rule{Name: "r", Job: "job", Backend: "batch", Batch: &BatchSpec{ImageUri: "image"}, Queue: true}, errorMsg: "cannot queue"},
+		{name: "bad template", rule: Rule{Name: "r", Job: "job", Args: []string{"{{.TraceId"}}, errorMsg: "argument template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(Config{Rules: []Rule{tt.rule}})
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Fatalf("error = %v, want %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestRenderArgs(t *testing.T) {
+	router, err := New(Config{Rules: []Rule{{Name: "r", Job: "job", Args: []string{"{{.TraceId}}", "{{.FileName}}"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rule, _ := router.Match(model.FileInfo{})
+	args, err := rule.RenderArgs(model.FileInfo{TraceId: "trace", FileName: "a.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(args, " ") != "trace a.json" {
+		t.Errorf("args = %v", args)
+	}
+}
+
+func TestBuiltInRules(t *testing.T) {
+	router, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		extension string
+		job       string
+	}{
+		{extension: ".json", job: "prj-wayne-file-streamer"},
+		{extension: ".gz", job: "prj-wayne-gz-streamer"},
+		{extension: ".tgz", job: "prj-wayne-gz-streamer"},
+		{extension: ".zip", job: "prj-wayne-zip-downloader"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.extension, func(t *testing.T) {
+			rule, ok := router.Match(model.FileInfo{FileExtension: tt.extension})
+			if !ok {
+				t.Fatal("no rule matched")
+			}
+			if rule.Job != tt.job {
+				t.Errorf("job = %q, want %q", rule.Job, tt.job)
+			}
+		})
+	}
+}
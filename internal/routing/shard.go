@@ -0,0 +1,68 @@
+package routing
+
+import (
+	"fmt"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+)
+
+// ShardSpec splits large files served with range support into byte-range
+// shards, one Cloud Run task per shard.
+type ShardSpec struct {
+	ShardSizeMB int64 `json:"shardSizeMB,omitempty"` // defaults to constants.DEFAULT_SHARD_SIZE_MB
+	MaxShards   int   `json:"maxShards,omitempty"`   // defaults to constants.DEFAULT_MAX_SHARDS
+}
+
+// validate fills in defaults and checks the limits.
+func (s *ShardSpec) validate(ruleName string) error {
+	if s.ShardSizeMB < 0 || s.MaxShards < 0 {
+		return fmt.Errorf("routing rule %q shard spec values must not be negative", ruleName)
+	}
+	if s.ShardSizeMB == 0 {
+		s.ShardSizeMB = constants.DEFAULT_SHARD_SIZE_MB
+	}
+	if s.MaxShards == 0 {
+		s.MaxShards = constants.DEFAULT_MAX_SHARDS
+	}
+	if s.MaxShards > constants.SHARD_LIMIT {
+		return fmt.Errorf("routing rule %q maxShards exceeds the limit of %d", ruleName, constants.SHARD_LIMIT)
+	}
+	return nil
+}
+
+// ShardManifest splits the file into contiguous byte ranges of the rule's
+// shard size. When that would exceed the maximum shard count, the shards
+// are enlarged so the file fits in the maximum. It returns nil when the rule
+// does not shard, the server does not support ranges, the size is unknown or
+// the file fits in a single shard.
+func (rule *Rule) ShardManifest(info model.FileInfo) *model.ShardManifest {
+	spec := rule.Shard
+	if spec == nil || !info.RangeSupported || info.SizeUnknown {
+		return nil
+	}
+
+	shardSize := spec.ShardSizeMB << 20
+	count := (info.SizeBytes + shardSize - 1) / shardSize
+	if count <= 1 {
+		return nil
+	}
+	if count > int64(spec.MaxShards) {
+		count = int64(spec.MaxShards)
+		shardSize = (info.SizeBytes + count - 1) / count
+	}
+
+	manifest := &model.ShardManifest{
+		FileUrl:        info.FIleUrl,
+		SizeBytes:      info.SizeBytes,
+		ShardSizeBytes: shardSize,
+	}
+	for start := int64(0); start < info.SizeBytes; start += shardSize {
+		manifest.Shards = append(manifest.Shards, model.ByteRange{
+			Index: len(manifest.Shards),
+			Start: start,
+			End:   min(start+shardSize, info.SizeBytes) - 1,
+		})
+	}
+	return manifest
+}
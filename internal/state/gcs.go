@@ -0,0 +1,195 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+)
+
+// GCSStore keeps request state in a Cloud Storage bucket, so a status poll
+// can be answered by any instance. Each request is stored under
+// <prefix>/<traceId>/: the request itself in state.json and every finished
+// file in results/<index>.json, so the worker goroutines never write the
+// same object. The store does not delete anything; a lifecycle rule on the
+// prefix should expire old requests.
+type GCSStore struct {
+	bucket *storage.BucketHandle
+	prefix string
+}
+
+// NewGCSStore returns a store writing under prefix in the bucket.
+func NewGCSStore(client *storage.Client, bucket string, prefix string) *GCSStore {
+	return &GCSStore{bucket: client.Bucket(bucket), prefix: strings.TrimSuffix(prefix, "/")}
+}
+
+func (s *GCSStore) Create(ctx context.Context, state model.RequestState) error {
+	object := s.stateObject(state.TraceId).If(storage.Conditions{DoesNotExist: true})
+	err := writeJSON(ctx, object, state)
+	if isPreconditionFailed(err) {
+		return fmt.Errorf("request %s already exists", state.TraceId)
+	}
+	return err
+}
+
+func (s *GCSStore) SetStatus(ctx context.Context, traceId string, status string) error {
+	return s.update(ctx, traceId, func(state *model.RequestState) {
+		state.Status = status
+	})
+}
+
+func (s *GCSStore) UpdateResult(ctx context.Context, traceId string, index int, result model.FileInfo) error {
+	if index < 0 {
+		return fmt.Errorf("result index %d out of range for request %s", index, traceId)
+	}
+	return writeJSON(ctx, s.bucket.Object(s.resultsPrefix(traceId)+strconv.Itoa(index)+".json"), result)
+}
+
+func (s *GCSStore) Complete(ctx context.Context, traceId string, results []model.FileInfo, summary model.ResultSummary) error {
+	return s.update(ctx, traceId, func(state *model.RequestState) {
+		now := time.Now()
+		state.Status = constants.REQUEST_STATUS_COMPLETED
+		state.Results = slices.Clone(results)
+		state.Summary = &summary
+		state.CompletedAt = &now
+	})
+}
+
+// Get reads the request and, until it has completed, merges in the results
+// of the files that have finished.
+func (s *GCSStore) Get(ctx context.Context, traceId string) (model.RequestState, error) {
+	state, _, err := s.read(ctx, traceId)
+	if err != nil || state.CompletedAt != nil {
+		return state, err
+	}
+
+	objects := s.bucket.Objects(ctx, &storage.Query{Prefix: s.resultsPrefix(traceId)})
+	for {
+		attrs, err := objects.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return model.RequestState{}, fmt.Errorf("unable to list results of request %s: %v", traceId, err)
+		}
+		index, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(attrs.Name, s.resultsPrefix(traceId)), ".json"))
+		if err != nil || index >= len(state.Results) {
+			continue
+		}
+		var result model.FileInfo
+		if _, err := readJSON(ctx, s.bucket.Object(attrs.Name), &result); err != nil {
+			return model.RequestState{}, err
+		}
+		state.Results[index] = result
+		state.UpdatedAt = latest(state.UpdatedAt, attrs.Updated)
+	}
+	return state, nil
+}
+
+// update applies change to the stored request. The write is conditional on
+// the generation that was read and is retried when another writer got there
+// first.
+func (s *GCSStore) update(ctx context.Context, traceId string, change func(state *model.RequestState)) error {
+	for range constants.STATE_UPDATE_ATTEMPTS {
+		state, generation, err := s.read(ctx, traceId)
+		if err != nil {
+			return err
+		}
+		change(&state)
+		state.UpdatedAt = time.Now()
+
+		object := s.stateObject(traceId).If(storage.Conditions{GenerationMatch: generation})
+		if err := writeJSON(ctx, object, state); !isPreconditionFailed(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("request %s was updated concurrently", traceId)
+}
+
+// read returns the stored request and the generation of its object.
+func (s *GCSStore) read(ctx context.Context, traceId string) (model.RequestState, int64, error) {
+	var state model.RequestState
+	generation, err := readJSON(ctx, s.stateObject(traceId), &state)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return model.RequestState{}, 0, ErrNotFound
+	}
+	return state, generation, err
+}
+
+func (s *GCSStore) stateObject(traceId string) *storage.ObjectHandle {
+	return s.bucket.Object(s.prefix + "/" + traceId + "/state.json")
+}
+
+func (s *GCSStore) resultsPrefix(traceId string) string {
+	return s.prefix + "/" + traceId + "/results/"
+}
+
+// readJSON decodes the object into value and returns its generation.
+func readJSON(ctx context.Context, object *storage.ObjectHandle, value any) (int64, error) {
+	reader, err := object.NewReader(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return 0, err
+	}
+	if err != nil {
+		return 0, fmt.Errorf("unable to read object %s: %v", object.ObjectName(), err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return 0, fmt.Errorf("unable to read object %s: %v", object.ObjectName(), err)
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return 0, fmt.Errorf("invalid object %s: %v", object.ObjectName(), err)
+	}
+	return reader.Attrs.Generation, nil
+}
+
+// writeJSON stores value as the object's JSON content. Precondition failures
+// are returned unwrapped so callers can detect them.
+func writeJSON(ctx context.Context, object *storage.ObjectHandle, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("unable to encode object %s: %v", object.ObjectName(), err)
+	}
+
+	writer := object.NewWriter(ctx)
+	writer.ContentType = constants.APPLICATION_JSON
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return fmt.Errorf("unable to write object %s: %v", object.ObjectName(), err)
+	}
+	if err := writer.Close(); err != nil {
+		if isPreconditionFailed(err) {
+			return err
+		}
+		return fmt.Errorf("unable to write object %s: %v", object.ObjectName(), err)
+	}
+	return nil
+}
+
+// isPreconditionFailed reports whether a conditional write lost to another
+// writer.
+func isPreconditionFailed(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusPreconditionFailed
+}
+
+func latest(a time.Time, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,113 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+)
+
+// MemoryStore keeps request state in process memory. It is meant for local
+// runs only: state is lost on restart and is not shared between instances,
+// so a poll answered by another instance would not find the request.
+// Completed requests are dropped once they are older than the retention
+// period.
+type MemoryStore struct {
+	mu        sync.Mutex
+	retention time.Duration
+	requests  map[string]*model.RequestState
+}
+
+// NewMemoryStore returns an empty in-memory store that keeps completed
+// requests for retentionHours.
+func NewMemoryStore(retentionHours int) *MemoryStore {
+	return &MemoryStore{
+		retention: time.Duration(retentionHours) * time.Hour,
+		requests:  map[string]*model.RequestState{},
+	}
+}
+
+func (s *MemoryStore) Create(ctx context.Context, state model.RequestState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictExpired(time.Now())
+	if _, ok := s.requests[state.TraceId]; ok {
+		return fmt.Errorf("request %s already exists", state.TraceId)
+	}
+	state.Results = slices.Clone(state.Results)
+	s.requests[state.TraceId] = &state
+	return nil
+}
+
+func (s *MemoryStore) SetStatus(ctx context.Context, traceId string, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.requests[traceId]
+	if !ok {
+		return ErrNotFound
+	}
+	state.Status = status
+	state.UpdatedAt = time.Now()
+	return nil
+}
+
+func (s *MemoryStore) UpdateResult(ctx context.Context, traceId string, index int, result model.FileInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.requests[traceId]
+	if !ok {
+		return ErrNotFound
+	}
+	if index < 0 || index >= len(state.Results) {
+		return fmt.Errorf("result index %d out of range for request %s", index, traceId)
+	}
+	state.Results[index] = result
+	state.UpdatedAt = time.Now()
+	return nil
+}
+
+func (s *MemoryStore) Complete(ctx context.Context, traceId string, results []model.FileInfo, summary model.ResultSummary) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.requests[traceId]
+	if !ok {
+		return ErrNotFound
+	}
+	now := time.Now()
+	state.Status = constants.REQUEST_STATUS_COMPLETED
+	state.Results = slices.Clone(results)
+	state.Summary = &summary
+	state.UpdatedAt = now
+	state.CompletedAt = &now
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, traceId string) (model.RequestState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.requests[traceId]
+	if !ok {
+		return model.RequestState{}, ErrNotFound
+	}
+	copied := *state
+	copied.Results = slices.Clone(state.Results)
+	return copied, nil
+}
+
+// evictExpired drops completed requests older than the retention period.
+func (s *MemoryStore) evictExpired(now time.Time) {
+	for traceId, state := range s.requests {
+		if state.CompletedAt != nil && now.Sub(*state.CompletedAt) > s.retention {
+			delete(s.requests, traceId)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Package state keeps the progress of asynchronous requests so that it can be
+// polled through the status endpoint while the batch is processed in the
+// background.
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/storage"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+)
+
+// ErrNotFound is returned when no request is stored under a trace ID.
+var ErrNotFound = errors.New("request not found")
+
+// Store persists request progress. Implementations must be safe for
+// concurrent use, as file results are reported from the worker goroutines.
+type Store interface {
+	// Create records a newly accepted request.
+	Create(ctx context.Context, state model.RequestState) error
+	// SetStatus updates the request status.
+	SetStatus(ctx context.Context, traceId string, status string) error
+	// UpdateResult records the result of the file at index.
+	UpdateResult(ctx context.Context, traceId string, index int, result model.FileInfo) error
+	// Complete records the final results and summary of the request.
+	Complete(ctx context.Context, traceId string, results []model.FileInfo, summary model.ResultSummary) error
+	// Get returns the request stored under the trace ID, or ErrNotFound.
+	Get(ctx context.Context, traceId string) (model.RequestState, error)
+}
+
+// New returns the store selected by kind. An empty kind selects the Cloud
+// Storage store writing to bucket; the in-memory store is only suitable for
+// local runs, as it is not shared between instances.
+func New(ctx context.Context, kind string, bucket string) (Store, error) {
+	switch kind {
+	case "", constants.STATE_STORE_GCS:
+		client, err := storage.NewClient(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create storage client: %v", err)
+		}
+		return NewGCSStore(client, bucket, constants.STATE_OBJECT_PREFIX), nil
+	case constants.STATE_STORE_MEMORY:
+		return NewMemoryStore(constants.STATE_RETENTION_HOURS), nil
+	default:
+		return nil, fmt.Errorf("unknown state store %q", kind)
+	}
+}
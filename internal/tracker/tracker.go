@@ -0,0 +1,110 @@
+// Package tracker follows triggered Cloud Run executions to their terminal
+// state and records the outcome in the audit table.
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
+	"go.uber.org/zap"
+)
+
+// ExecutionWaiter waits for the execution started by a RunJob operation to
+// finish. It is implemented by *compute.Compute and can be faked in tests.
+type ExecutionWaiter interface {
+	WaitExecution(ctx context.Context, operation string) (model.ExecutionOutcome, error)
+}
+
+// AuditLogger writes audit events. It is implemented by the audit sinks.
+type AuditLogger interface {
+	LogAuditData(ctx context.Context, event model.AuditEvent) error
+}
+
+// ExecutionTracker waits on triggered executions in the background, each
+// bounded by a timeout, and writes one audit event per execution with its
+// terminal state.
+type ExecutionTracker struct {
+	logger  *zap.Logger
+	traceId string
+	waiter  ExecutionWaiter
+	audit   AuditLogger
+	timeout time.Duration
+	wg      sync.WaitGroup
+}
+
+// NewExecutionTracker creates a tracker that gives up on an execution after
+// timeout.
+func NewExecutionTracker(logger *zap.Logger, traceId string, waiter ExecutionWaiter, audit AuditLogger, timeout time.Duration) *ExecutionTracker {
+	return &ExecutionTracker{
+		logger:  logger,
+		traceId: traceId,
+		waiter:  waiter,
+		audit:   audit,
+		timeout: timeout,
+	}
+}
+
+// Track starts following the operation in the background. The wait is
+// detached from ctx so that it outlives the HTTP request that triggered it.
+// The optional onDone is called with the outcome once it has been recorded.
+func (t *ExecutionTracker) Track(ctx context.Context, fileUrl string, operation string, onDone func(outcome model.ExecutionOutcome)) {
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), t.timeout)
+		defer cancel()
+
+		outcome, err := t.waiter.WaitExecution(ctx, operation)
+		if err != nil {
+			outcome.Operation = operation
+			outcome.State = constants.EXECUTION_TIMED_OUT
+			outcome.Message = err.Error()
+		}
+		t.record(context.WithoutCancel(ctx), fileUrl, outcome)
+		if onDone != nil {
+			onDone(outcome)
+		}
+	}()
+}
+
+// Wait blocks until every tracked execution has been recorded.
+func (t *ExecutionTracker) Wait() {
+	t.wg.Wait()
+}
+
+// record writes the terminal state of an execution to the audit table.
+func (t *ExecutionTracker) record(ctx context.Context, fileUrl string, outcome model.ExecutionOutcome) {
+	event, status := constants.CLOUD_RUN_EXECUTION_FAILED, constants.FAILED
+	switch outcome.State {
+	case constants.EXECUTION_SUCCEEDED:
+		event, status = constants.CLOUD_RUN_EXECUTION_SUCCEEDED, constants.COMPLETED
+	case constants.EXECUTION_CANCELLED:
+		event = constants.CLOUD_RUN_EXECUTION_CANCELLED
+	case constants.EXECUTION_TIMED_OUT:
+		event, status = constants.CLOUD_RUN_EXECUTION_TIMED_OUT, constants.IN_PROGRESS
+	}
+
+	message, _ := json.Marshal(outcome)
+	t.logger.Info("cloud run execution tracked",
+		zap.String("applicationName", constants.APPLICATION_NAME),
+		zap.String("traceId", t.traceId),
+		zap.String("fileUrl", fileUrl),
+		zap.String("operation", outcome.Operation),
+		zap.String("state", outcome.State))
+
+	t.audit.LogAuditData(ctx, model.AuditEvent{
+		TraceID:      t.traceId,
+		ContractId:   t.traceId,
+		FileUrl:      fileUrl,
+		Event:        event,
+		Status:       status,
+		Timestamp:    time.Now(),
+		FunctionName: constants.APPLICATION_NAME,
+		Message:      string(message),
+	})
+}
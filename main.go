@@ -4,26 +4,312 @@
 package decider
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/audit"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/batch"
 	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/bigquery"
 	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/compute"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/dispatcher"
 	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/gcs"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/launcher"
 	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/model"
 	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/processor"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/routing"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/state"
+	"github.com/AmithSAI007/prj-wayne-compute-decider.git/internal/tracker"
 	"github.com/AmithSAI007/prj-wayne-compute-decider.git/pkg/constants"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+var (
+	routerOnce sync.Once
+	router     *routing.Router
+	routerErr  error
+
+	auditSinkOnce sync.Once
+	auditSink     audit.AuditSink
+	auditSinkErr  error
+	auditWriter   atomic.Pointer[bigquery.AuditWriter]
+
+	stateStoreOnce sync.Once
+	stateStore     state.Store
+	stateStoreErr  error
+
+	localLauncherOnce sync.Once
+	localLauncher     *launcher.Local
+	localLauncherErr  error
+
+	// redispatches coalesces the dispatch passes started by finished
+	// executions across every request of the instance
+	redispatches dispatcher.Coalescer
+
+	schemaMu      sync.Mutex
+	schemaChecked bool
+	schemaErr     error
+)
+
+// loadRouter compiles the routing rules once per instance. The rules file is
+// read from ROUTING_RULES_PATH, falling back to the built-in rules.
+func loadRouter() (*routing.Router, error) {
+	routerOnce.Do(func() {
+		router, routerErr = routing.Load(os.Getenv(constants.ROUTING_RULES_PATH))
+	})
+	return router, routerErr
+}
+
+// loadStateStore creates the request state store once per instance, selected
+// by STATE_STORE and defaulting to the Cloud Storage store in STATE_BUCKET,
+// or the media bucket when it is not set.
+func loadStateStore() (state.Store, error) {
+	stateStoreOnce.Do(func() {
+		bucket := os.Getenv(constants.STATE_BUCKET)
+		if bucket == "" {
+			bucket = constants.HARDCODED_BUCKET_NAME
+		}
+		stateStore, stateStoreErr = state.New(context.Background(), os.Getenv(constants.STATE_STORE), bucket)
+	})
+	return stateStore, stateStoreErr
+}
+
+// loadSchema checks the BigQuery dataset and tables once per instance, on the
+// first request, as selected by SCHEMA_BOOTSTRAP: "migrate" (the default)
+// creates what is missing and adds new columns, "verify" only reports
+// differences and "off" skips the check. Schema drift and an unknown mode are
+// final, while other errors are retried by the next request.
+func loadSchema(ctx context.Context, client *bigquery.Client) error {
+	schemaMu.Lock()
+	defer schemaMu.Unlock()
+	if schemaChecked {
+		return schemaErr
+	}
+
+	mode := os.Getenv(constants.SCHEMA_BOOTSTRAP)
+	switch mode {
+	case "", constants.SCHEMA_MIGRATE, constants.SCHEMA_VERIFY:
+	case constants.SCHEMA_OFF:
+		schemaChecked = true
+		return nil
+	default:
+		schemaChecked = true
+		schemaErr = fmt.Errorf("unknown schema bootstrap mode %q", mode)
+		return schemaErr
+	}
+
+	// The check outlives the request that happens to run it
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), constants.SCHEMA_BOOTSTRAP_SECONDS*time.Second)
+	defer cancel()
+	err := client.EnsureSchema(ctx, mode != constants.SCHEMA_VERIFY)
+	if err == nil || errors.Is(err, bigquery.ErrSchemaDrift) {
+		schemaChecked = true
+		schemaErr = err
+	}
+	return err
+}
+
+// loadAuditSink builds the audit sinks shared by every request from
+// AUDIT_SINK, a comma-separated list of "bigquery" (the default), "file",
+// "log" and "memory" written to together. AUDIT_FALLBACK_SINK names a local
+// sink that takes over the events BigQuery rejects. The sinks are closed,
+// flushing buffered events, when the instance receives SIGTERM.
+func loadAuditSink(logger *zap.Logger, projectId string) (audit.AuditSink, error) {
+	auditSinkOnce.Do(func() {
+		auditSink, auditSinkErr = newAuditSink(logger, projectId)
+		if auditSinkErr == nil {
+			go closeOnShutdown(logger, auditSink)
+		}
+	})
+	return auditSink, auditSinkErr
+}
+
+// newAuditSink creates the sinks selected by AUDIT_SINK.
+func newAuditSink(logger *zap.Logger, projectId string) (audit.AuditSink, error) {
+	var fallback audit.AuditSink
+	if kind := os.Getenv(constants.AUDIT_FALLBACK_SINK); kind != "" {
+		var err error
+		if fallback, err = newLocalAuditSink(logger, kind); err != nil {
+			return nil, err
+		}
+	}
+
+	kinds := os.Getenv(constants.AUDIT_SINK)
+	if kinds == "" {
+		kinds = constants.AUDIT_SINK_BIGQUERY
+	}
+	var sinks []audit.AuditSink
+	for _, kind := range strings.Split(kinds, ",") {
+		kind = strings.TrimSpace(kind)
+		var sink audit.AuditSink
+		var err error
+		if kind == constants.AUDIT_SINK_BIGQUERY {
+			sink, err = newBigQueryAuditSink(logger, projectId, fallback)
+		} else {
+			sink, err = newLocalAuditSink(logger, kind)
+		}
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	if len(sinks) == 1 {
+		return sinks[0], nil
+	}
+	return audit.FanOut(sinks...), nil
+}
+
+// newBigQueryAuditSink creates the BigQuery sink: the buffered audit writer,
+// or one synchronous insert per event when AUDIT_WRITER is "sync". With a
+// fallback sink, events BigQuery rejects or the writer cannot buffer are
+// written there instead.
+func newBigQueryAuditSink(logger *zap.Logger, projectId string, fallback audit.AuditSink) (audit.AuditSink, error) {
+	var sink audit.AuditSink
+	switch kind := os.Getenv(constants.AUDIT_WRITER); kind {
+	case "", constants.AUDIT_WRITER_BUFFERED:
+		writer, err := bigquery.NewAuditWriter(context.Background(), logger, projectId, bigquery.AuditWriterConfig{
+			BufferSize:    envInt(constants.AUDIT_BUFFER_SIZE, constants.DEFAULT_AUDIT_BUFFER_SIZE),
+			BatchSize:     envInt(constants.AUDIT_BATCH_SIZE, constants.DEFAULT_AUDIT_BATCH_SIZE),
+			FlushInterval: time.Duration(envInt(constants.AUDIT_FLUSH_INTERVAL, constants.DEFAULT_AUDIT_FLUSH_MS)) * time.Millisecond,
+			Fallback:      fallback,
+		})
+		if err != nil {
+			return nil, err
+		}
+		auditWriter.Store(writer)
+		sink = writer
+	case constants.AUDIT_WRITER_SYNC:
+		client, err := bigquery.NewClient(context.Background(), logger, projectId, "")
+		if err != nil {
+			return nil, err
+		}
+		sink = client
+	default:
+		return nil, fmt.Errorf("unknown audit writer %q", kind)
+	}
+
+	if fallback != nil {
+		return audit.NewFallback(sink, fallback), nil
+	}
+	return sink, nil
+}
+
+// newLocalAuditSink creates a sink that does not depend on BigQuery.
+func newLocalAuditSink(logger *zap.Logger, kind string) (audit.AuditSink, error) {
+	switch kind {
+	case constants.AUDIT_SINK_FILE:
+		path := os.Getenv(constants.AUDIT_FILE_PATH)
+		if path == "" {
+			return nil, fmt.Errorf("%s is required by the file audit sink", constants.AUDIT_FILE_PATH)
+		}
+		return audit.NewFile(path)
+	case constants.AUDIT_SINK_LOG:
+		return audit.NewLog(logger), nil
+	case constants.AUDIT_SINK_MEMORY:
+		return audit.NewMemory(), nil
+	default:
+		return nil, fmt.Errorf("unknown audit sink %q", kind)
+	}
+}
+
+// closeOnShutdown closes the audit sink on SIGTERM so buffered events are
+// written before the instance stops, then re-raises the signal.
+func closeOnShutdown(logger *zap.Logger, sink audit.AuditSink) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM)
+	<-signals
+
+	ctx, cancel := context.WithTimeout(context.Background(), constants.AUDIT_SHUTDOWN_FLUSH_SECONDS*time.Second)
+	defer cancel()
+	if err := sink.Close(ctx); err != nil {
+		logger.Error("unable to flush audit events on shutdown",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.Error(err))
+	}
+
+	signal.Stop(signals)
+	if process, err := os.FindProcess(os.Getpid()); err == nil {
+		process.Signal(syscall.SIGTERM)
+	}
+}
+
+// newLauncher creates the job launcher selected by JOB_LAUNCHER together with
+// the waiter the execution tracker uses to follow its jobs. The default cloud
+// launcher runs jobs on Cloud Run, and on Cloud Batch when a routing rule uses
+// that backend; "local" runs the commands configured in LOCAL_JOBS_CONFIG and
+// "memory" only records the jobs.
+func newLauncher(ctx context.Context, logger *zap.Logger, traceId string, projectId string, region string) (launcher.JobLauncher, tracker.ExecutionWaiter, error) {
+	switch kind := os.Getenv(constants.JOB_LAUNCHER); kind {
+	case "", constants.LAUNCHER_CLOUD:
+		compute, err := compute.NewCompute(ctx, logger, traceId)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to create cloud run job client: %v", err)
+		}
+		backends := launcher.Backends{constants.BACKEND_CLOUD_RUN: launcher.NewCloudRun(compute, projectId, region)}
+
+		// The Cloud Batch client is only needed when a routing rule uses it
+		if router, err := loadRouter(); err == nil && router.UsesBackend(constants.BACKEND_BATCH) {
+			batchClient, err := batch.NewBatch(ctx, logger, traceId)
+			if err != nil {
+				return nil, nil, fmt.Errorf("unable to create cloud batch client: %v", err)
+			}
+			backends[constants.BACKEND_BATCH] = launcher.NewCloudBatch(batchClient, projectId, region)
+		}
+		return backends, compute, nil
+	case constants.LAUNCHER_LOCAL:
+		local, err := loadLocalLauncher(logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		return local, local, nil
+	case constants.LAUNCHER_MEMORY:
+		memory := launcher.NewMemory()
+		return memory, memory, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown job launcher %q", kind)
+	}
+}
+
+// loadLocalLauncher creates the local launcher once per instance, so a later
+// request can wait on the processes an earlier one started.
+func loadLocalLauncher(logger *zap.Logger) (*launcher.Local, error) {
+	localLauncherOnce.Do(func() {
+		localLauncher, localLauncherErr = launcher.LoadLocal(logger, "", os.Getenv(constants.LOCAL_JOBS_CONFIG))
+	})
+	return localLauncher, localLauncherErr
+}
+
+// envInt reads a positive integer from the environment, falling back to the
+// default when the variable is unset or invalid.
+func envInt(name string, def int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value <= 0 {
+		return def
+	}
+	return value
+}
+
 // AnalyzeFileHandler is the main HTTP handler function for the Cloud Function.
 // It validates the incoming request, initializes required clients, logs audit events,
 // and delegates file analysis to the processor. Results are returned as a JSON response.
+// POST /plan, or any request with ?dryRun=true, reports what would happen for each
+// URL without triggering jobs; /plan is always a dry run. With ?async=true the
+// batch is accepted with 202 and processed in the background; its progress is
+// served by GET /requests/{traceId}.
+// POST /dispatch launches the files queued in the contract file queue.
 func AnalyzeFileHandler(w http.ResponseWriter, r *http.Request) {
 	traceId := uuid.New().String()
 
@@ -57,8 +343,19 @@ func AnalyzeFileHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if r.Method == http.MethodGet && r.URL.Path == constants.HEALTH {
+		response := model.HealthResponse{Status: "ok"}
+		if writer := auditWriter.Load(); writer != nil {
+			stats := writer.Stats()
+			response.Audit = &stats
+		}
+		w.Header().Set(constants.CONTENT_TYPE, constants.APPLICATION_JSON)
 		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"status":"ok"}`))
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, constants.REQUESTS) {
+		requestStatusHandler(w, r, logger, traceId)
 		return
 	}
 
@@ -74,20 +371,54 @@ func AnalyzeFileHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Initialize Compute client
-	compute, err := compute.NewCompute(ctx, logger, traceId)
+	// Audit events go to the configured sinks, or straight to BigQuery when
+	// those cannot be created
+	var sink audit.AuditSink = client
+	if configured, err := loadAuditSink(logger, projectId); err != nil {
+		logger.Warn("unable to create audit sinks, writing audit events to bigquery",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", traceId),
+			zap.Error(err))
+	} else {
+		sink = configured
+	}
+
+	// Checked by the first request of the instance, and again by later ones
+	// only if BigQuery could not be reached
+	if err := loadSchema(ctx, client); err != nil {
+		logger.Error("bigquery schema check failed",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", traceId),
+			zap.Error(err))
+
+		sink.LogAuditData(ctx, model.AuditEvent{
+			TraceID:      traceId,
+			ContractId:   traceId,
+			Event:        constants.SCHEMA_CHECK_FAILED,
+			Status:       constants.FAILED,
+			Timestamp:    time.Now(),
+			FunctionName: constants.APPLICATION_NAME,
+			Message:      err.Error(),
+		})
+
+		http.Error(w, "bigquery schema check failed", http.StatusInternalServerError)
+		return
+	}
+
+	// Initialize the job launcher for the configured backend
+	jobLauncher, waiter, err := newLauncher(ctx, logger, traceId, projectId, projectRegion)
 	if err != nil {
-		logger.Error("unable to create cloud run job client",
+		logger.Error("unable to create job launcher",
 			zap.String("applicationName", constants.APPLICATION_NAME),
 			zap.String("traceId", traceId),
 			zap.Error(err))
 
-		http.Error(w, "unable to create cloud run job client", http.StatusBadRequest)
+		http.Error(w, "unable to create job launcher", http.StatusBadRequest)
 		return
 	}
 
 	// Log application start event
-	client.LogAuditData(ctx, model.AuditEvent{
+	sink.LogAuditData(ctx, model.AuditEvent{
 		TraceID:      traceId,
 		ContractId:   traceId,
 		Event:        constants.APPLICATION_STARTED_EVENT,
@@ -97,6 +428,32 @@ func AnalyzeFileHandler(w http.ResponseWriter, r *http.Request) {
 		Message:      "application started",
 	})
 
+	router, err := loadRouter()
+	if err != nil {
+		logger.Error("invalid routing rules",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", traceId),
+			zap.Error(err))
+
+		sink.LogAuditData(ctx, model.AuditEvent{
+			TraceID:      traceId,
+			ContractId:   traceId,
+			Event:        constants.ROUTING_RULES_INVALID,
+			Status:       constants.FAILED,
+			Timestamp:    time.Now(),
+			FunctionName: constants.APPLICATION_NAME,
+			Message:      err.Error(),
+		})
+
+		http.Error(w, "invalid routing rules", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Method == http.MethodPost && r.URL.Path == constants.DISPATCH {
+		dispatchHandler(w, r, logger, traceId, sink, client, jobLauncher, waiter, router)
+		return
+	}
+
 	// Read and parse request body
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -104,7 +461,7 @@ func AnalyzeFileHandler(w http.ResponseWriter, r *http.Request) {
 			zap.String("applicationName", constants.APPLICATION_NAME),
 			zap.String("traceId", traceId),
 			zap.Error(err))
-		client.LogAuditData(ctx, model.AuditEvent{
+		sink.LogAuditData(ctx, model.AuditEvent{
 			TraceID:      traceId,
 			ContractId:   traceId,
 			Event:        constants.REQUEST_BODY_FAILED,
@@ -127,7 +484,7 @@ func AnalyzeFileHandler(w http.ResponseWriter, r *http.Request) {
 			zap.String("traceId", traceId),
 			zap.Error(err))
 
-		client.LogAuditData(ctx, model.AuditEvent{
+		sink.LogAuditData(ctx, model.AuditEvent{
 			TraceID:      traceId,
 			ContractId:   traceId,
 			Event:        constants.INVALID_JSON_FORMAT,
@@ -149,7 +506,7 @@ func AnalyzeFileHandler(w http.ResponseWriter, r *http.Request) {
 			zap.String("traceId", traceId),
 			zap.String("message", "Missing fileUrl Parameter"))
 
-		client.LogAuditData(ctx, model.AuditEvent{
+		sink.LogAuditData(ctx, model.AuditEvent{
 			TraceID:      traceId,
 			ContractId:   traceId,
 			Event:        constants.FILE_URL_MISSING,
@@ -170,7 +527,7 @@ func AnalyzeFileHandler(w http.ResponseWriter, r *http.Request) {
 			zap.String("traceId", traceId),
 			zap.Error(err))
 
-		client.LogAuditData(ctx, model.AuditEvent{
+		sink.LogAuditData(ctx, model.AuditEvent{
 			TraceID:      traceId,
 			ContractId:   traceId,
 			Event:        constants.ERROR_CREATING_GCS_CLIENT,
@@ -185,22 +542,301 @@ func AnalyzeFileHandler(w http.ResponseWriter, r *http.Request) {
 
 	}
 
+	// A plan request runs the full decision path without triggering jobs, and
+	// dryRun=false cannot turn it into a real run
+	dryRun := r.URL.Path == constants.PLAN
+	if value := r.URL.Query().Get(constants.DRY_RUN_PARAM); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			logger.Error("invalid dryRun parameter",
+				zap.String("applicationName", constants.APPLICATION_NAME),
+				zap.String("traceId", traceId),
+				zap.String("dryRun", value))
+
+			sink.LogAuditData(ctx, model.AuditEvent{
+				TraceID:      traceId,
+				ContractId:   traceId,
+				Event:        constants.INVALID_DRY_RUN_PARAM,
+				Status:       constants.FAILED,
+				Timestamp:    time.Now(),
+				FunctionName: constants.APPLICATION_NAME,
+				Message:      fmt.Sprintf("invalid dryRun parameter %q", value),
+			})
+
+			http.Error(w, "Invalid 'dryRun' parameter", http.StatusBadRequest)
+			return
+		}
+		dryRun = dryRun || parsed
+	}
+	async := false
+	if value := r.URL.Query().Get(constants.ASYNC_PARAM); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			logger.Error("invalid async parameter",
+				zap.String("applicationName", constants.APPLICATION_NAME),
+				zap.String("traceId", traceId),
+				zap.String("async", value))
+
+			sink.LogAuditData(ctx, model.AuditEvent{
+				TraceID:      traceId,
+				ContractId:   traceId,
+				Event:        constants.INVALID_ASYNC_PARAM,
+				Status:       constants.FAILED,
+				Timestamp:    time.Now(),
+				FunctionName: constants.APPLICATION_NAME,
+				Message:      fmt.Sprintf("invalid async parameter %q", value),
+			})
+
+			http.Error(w, "Invalid 'async' parameter", http.StatusBadRequest)
+			return
+		}
+		async = parsed
+	}
+
+	// Triggered executions are followed in the background to record their outcome
+	executionTimeout := time.Duration(envInt(constants.EXECUTION_TIMEOUT, constants.DEFAULT_EXECUTION_TIMEOUT_MINUTES)) * time.Minute
+	config := processor.Config{
+		Concurrency:        envInt(constants.ANALYZE_CONCURRENCY, constants.DEFAULT_ANALYZE_CONCURRENCY),
+		PerHostConcurrency: envInt(constants.PER_HOST_CONCURRENCY, constants.DEFAULT_PER_HOST_CONCURRENCY),
+		DryRun:             dryRun,
+		Tracker:            tracker.NewExecutionTracker(logger, traceId, waiter, sink, executionTimeout),
+		RunningWindow:      executionTimeout,
+		Dispatch: func(ctx context.Context) {
+			// Launch the queued files that were waiting for the finished one's slot
+			newDispatcher(logger, traceId, sink, client, jobLauncher, waiter, router).Redispatch(ctx)
+		},
+	}
+
+	if async {
+		store, err := loadStateStore()
+		if err == nil {
+			err = store.Create(ctx, pendingState(traceId, requestUUID, fileUrl, dryRun))
+		}
+		if err != nil {
+			logger.Error("unable to record async request",
+				zap.String("applicationName", constants.APPLICATION_NAME),
+				zap.String("traceId", traceId),
+				zap.Error(err))
+
+			sink.LogAuditData(ctx, model.AuditEvent{
+				TraceID:      traceId,
+				ContractId:   traceId,
+				Event:        constants.STATE_STORE_FAILED,
+				Status:       constants.FAILED,
+				Timestamp:    time.Now(),
+				FunctionName: constants.APPLICATION_NAME,
+				Message:      err.Error(),
+			})
+
+			http.Error(w, "unable to record async request", http.StatusInternalServerError)
+			return
+		}
+
+		sink.LogAuditData(ctx, model.AuditEvent{
+			TraceID:      traceId,
+			ContractId:   traceId,
+			Event:        constants.ASYNC_REQUEST_ACCEPTED,
+			Status:       constants.IN_PROGRESS,
+			Timestamp:    time.Now(),
+			FunctionName: constants.APPLICATION_NAME,
+			Message:      fmt.Sprintf("accepted %d files", len(fileUrl)),
+		})
+
+		// The request context is cancelled once the 202 is written, so the
+		// batch runs on a detached context
+		bgCtx := context.WithoutCancel(ctx)
+		config.OnResult = func(index int, result model.FileInfo) {
+			if err := store.UpdateResult(bgCtx, traceId, index, result); err != nil {
+				logger.Warn("unable to record file result",
+					zap.String("applicationName", constants.APPLICATION_NAME),
+					zap.String("traceId", traceId),
+					zap.String("fileUrl", result.FIleUrl),
+					zap.Error(err))
+			}
+		}
+		proc := processor.NewProcessor(traceId, fileUrl, logger, sink, client, jobLauncher, router, jobName, gcsClient, config)
+
+		go func() {
+			if err := store.SetStatus(bgCtx, traceId, constants.REQUEST_STATUS_RUNNING); err != nil {
+				// The batch still runs; pollers see it as accepted until it
+				// completes
+				logger.Warn("unable to record request status",
+					zap.String("applicationName", constants.APPLICATION_NAME),
+					zap.String("traceId", traceId),
+					zap.Error(err))
+
+				sink.LogAuditData(bgCtx, model.AuditEvent{
+					TraceID:      traceId,
+					ContractId:   traceId,
+					Event:        constants.STATE_STORE_FAILED,
+					Status:       constants.FAILED,
+					Timestamp:    time.Now(),
+					FunctionName: constants.APPLICATION_NAME,
+					Message:      err.Error(),
+				})
+			}
+			result := proc.AnalyzeFileUrls(bgCtx, fileUrl, requestUUID)
+			auditProbeFailures(bgCtx, logger, sink, traceId, result)
+			if err := store.Complete(bgCtx, traceId, result, processor.Summarize(result)); err != nil {
+				logger.Error("unable to record request completion",
+					zap.String("applicationName", constants.APPLICATION_NAME),
+					zap.String("traceId", traceId),
+					zap.Error(err))
+			}
+			auditCompleted(bgCtx, logger, sink, traceId)
+		}()
+
+		w.Header().Set(constants.CONTENT_TYPE, constants.APPLICATION_JSON)
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(model.AcceptedResponse{
+			TraceId:   traceId,
+			Status:    constants.REQUEST_STATUS_ACCEPTED,
+			StatusUrl: constants.REQUESTS + traceId,
+		})
+		return
+	}
+
 	// Instantiate processor and analyze the file
-	processor := processor.NewProcessor(traceId, fileUrl, logger, client, compute, projectId, projectRegion, jobName, gcsClient)
+	proc := processor.NewProcessor(traceId, fileUrl, logger, sink, client, jobLauncher, router, jobName, gcsClient, config)
 
-	result := processor.AnalyzeFileUrls(ctx, fileUrl, requestUUID)
+	result := proc.AnalyzeFileUrls(ctx, fileUrl, requestUUID)
+	auditProbeFailures(ctx, logger, sink, traceId, result)
 
+	// Respond with per-file results, 207 when any file did not succeed
+	response := model.AnalyzeResponse{
+		TraceId: traceId,
+		DryRun:  dryRun,
+		Summary: processor.Summarize(result),
+		Results: result,
+	}
+	statusCode := http.StatusOK
+	if response.Summary.Failed > 0 {
+		statusCode = http.StatusMultiStatus
+	}
 	w.Header().Set(constants.CONTENT_TYPE, constants.APPLICATION_JSON)
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(response)
+
+	auditCompleted(ctx, logger, sink, traceId)
+}
 
-	// Handle any errors from processing
+// newDispatcher creates a dispatcher tracking the executions it launches.
+// Entries dispatched longer than the execution tracking timeout ago no longer
+// count as running.
+func newDispatcher(logger *zap.Logger, traceId string, sink audit.AuditSink, queue dispatcher.Queue, jobLauncher launcher.JobLauncher, waiter tracker.ExecutionWaiter, router *routing.Router) *dispatcher.Dispatcher {
+	executionTimeout := time.Duration(envInt(constants.EXECUTION_TIMEOUT, constants.DEFAULT_EXECUTION_TIMEOUT_MINUTES)) * time.Minute
+	executionTracker := tracker.NewExecutionTracker(logger, traceId, waiter, sink, executionTimeout)
+	return dispatcher.NewDispatcher(logger, traceId, queue, sink, jobLauncher, waiter, executionTracker, router, executionTimeout, &redispatches)
+}
+
+// dispatchHandler serves POST /dispatch, launching queued contract files
+// within the per-job limits of the routing rules' dispatch policy.
+func dispatchHandler(w http.ResponseWriter, r *http.Request, logger *zap.Logger, traceId string, sink audit.AuditSink, queue dispatcher.Queue, jobLauncher launcher.JobLauncher, waiter tracker.ExecutionWaiter, router *routing.Router) {
+	ctx := r.Context()
+	d := newDispatcher(logger, traceId, sink, queue, jobLauncher, waiter, router)
+	response, err := d.Dispatch(ctx)
+	if err != nil {
+		logger.Error("dispatch failed",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", traceId),
+			zap.Error(err))
+
+		sink.LogAuditData(ctx, model.AuditEvent{
+			TraceID:      traceId,
+			ContractId:   traceId,
+			Event:        constants.DISPATCH_FAILED,
+			Status:       constants.FAILED,
+			Timestamp:    time.Now(),
+			FunctionName: constants.APPLICATION_NAME,
+			Message:      err.Error(),
+		})
+
+		http.Error(w, "dispatch failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(constants.CONTENT_TYPE, constants.APPLICATION_JSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+	auditCompleted(ctx, logger, sink, traceId)
+}
+
+// requestStatusHandler serves GET /requests/{traceId} with the progress of an
+// asynchronous request.
+func requestStatusHandler(w http.ResponseWriter, r *http.Request, logger *zap.Logger, traceId string) {
+	requestId := strings.TrimPrefix(r.URL.Path, constants.REQUESTS)
+	if requestId == "" || strings.Contains(requestId, "/") {
+		http.Error(w, "invalid request id", http.StatusBadRequest)
+		return
+	}
+
+	store, err := loadStateStore()
+	if err != nil {
+		logger.Error("unable to create state store",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", traceId),
+			zap.Error(err))
+
+		http.Error(w, "unable to create state store", http.StatusInternalServerError)
+		return
+	}
+
+	requestState, err := store.Get(r.Context(), requestId)
+	if errors.Is(err, state.ErrNotFound) {
+		http.Error(w, "request not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logger.Error("unable to read request state",
+			zap.String("applicationName", constants.APPLICATION_NAME),
+			zap.String("traceId", traceId),
+			zap.String("requestTraceId", requestId),
+			zap.Error(err))
+
+		http.Error(w, "unable to read request state", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(constants.CONTENT_TYPE, constants.APPLICATION_JSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(requestState)
+}
+
+// pendingState builds the initial state of an asynchronous request with every
+// file pending.
+func pendingState(traceId string, requestUUID string, fileUrls []string, dryRun bool) model.RequestState {
+	now := time.Now()
+	results := make([]model.FileInfo, len(fileUrls))
+	for i, fileUrl := range fileUrls {
+		results[i] = model.FileInfo{
+			TraceId:     traceId,
+			RequestUUID: requestUUID,
+			FIleUrl:     fileUrl,
+			Status:      constants.FILE_STATUS_PENDING,
+		}
+	}
+	return model.RequestState{
+		TraceId:     traceId,
+		RequestUUID: requestUUID,
+		Status:      constants.REQUEST_STATUS_ACCEPTED,
+		DryRun:      dryRun,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Results:     results,
+	}
+}
+
+// auditProbeFailures audits files whose probe failed; every other failure is
+// audited by the processor.
+func auditProbeFailures(ctx context.Context, logger *zap.Logger, sink audit.AuditSink, traceId string, result []model.FileInfo) {
 	for _, res := range result {
-		if res.Error != "" {
+		if res.Status == constants.FILE_STATUS_PROBE_FAILED {
 			logger.Error("error fetching file size",
 				zap.String("applicationName", constants.APPLICATION_NAME),
 				zap.String("traceId", traceId),
 				zap.String("error", res.Error))
 
-			client.LogAuditData(ctx, model.AuditEvent{
+			sink.LogAuditData(ctx, model.AuditEvent{
 				TraceID:      traceId,
 				ContractId:   traceId,
 				FileUrl:      res.FIleUrl,
@@ -210,17 +846,13 @@ func AnalyzeFileHandler(w http.ResponseWriter, r *http.Request) {
 				FunctionName: constants.APPLICATION_NAME,
 				Message:      res.Error,
 			})
-
-			http.Error(w, "error fetching file size", http.StatusInternalServerError)
-			return
 		}
 	}
+}
 
-	// Respond with result
-	json.NewEncoder(w).Encode(result)
-
-	// Log application completion event
-	client.LogAuditData(ctx, model.AuditEvent{
+// auditCompleted logs the application completion event.
+func auditCompleted(ctx context.Context, logger *zap.Logger, sink audit.AuditSink, traceId string) {
+	sink.LogAuditData(ctx, model.AuditEvent{
 		TraceID:      traceId,
 		ContractId:   traceId,
 		Event:        constants.APPLICATION_COMPLETED_EVENT,
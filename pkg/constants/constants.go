@@ -10,6 +10,14 @@ const (
 	RANGE_SUPPORTED      = "Accept-Ranges"
 	APPLICATION_JSON     = "application/json"
 	HEAD                 = "HEAD"
+	RANGE                = "Range"
+	CONTENT_RANGE        = "Content-Range"
+	CONTENT_ENCODING     = "Content-Encoding"
+	CONTENT_DISPOSITION  = "Content-Disposition"
+	CONTENT_MD5          = "Content-MD5"
+	ETAG                 = "ETag"
+	LAST_MODIFIED        = "Last-Modified"
+	X_GOOG_HASH          = "x-goog-hash"
 	CONTENT_LENGTH       = "Content-Length"
 	FILE_SIZE_BYTES      = 1073741824.0
 	REGION               = "us-central1"
@@ -20,6 +28,50 @@ const (
 	PROJECT_ID            = "GCP_PROJECT_ID"
 	BUCKET_NAME           = "BUCKET_NAME"
 	HARDCODED_BUCKET_NAME = "prj-wayne-media-bucket"
+	ROUTING_RULES_PATH    = "ROUTING_RULES_PATH"
+	ANALYZE_CONCURRENCY   = "ANALYZE_CONCURRENCY"
+	PER_HOST_CONCURRENCY  = "PER_HOST_CONCURRENCY"
+	STATE_STORE           = "STATE_STORE"
+	STATE_BUCKET          = "STATE_BUCKET"
+	EXECUTION_TIMEOUT     = "EXECUTION_TRACKING_TIMEOUT_MINUTES"
+	JOB_LAUNCHER          = "JOB_LAUNCHER"
+	LOCAL_JOBS_CONFIG     = "LOCAL_JOBS_CONFIG"
+	AUDIT_SINK            = "AUDIT_SINK"
+	AUDIT_FALLBACK_SINK   = "AUDIT_FALLBACK_SINK"
+	AUDIT_FILE_PATH       = "AUDIT_FILE_PATH"
+	AUDIT_WRITER          = "AUDIT_WRITER"
+	AUDIT_BUFFER_SIZE     = "AUDIT_BUFFER_SIZE"
+	AUDIT_BATCH_SIZE      = "AUDIT_BATCH_SIZE"
+	AUDIT_FLUSH_INTERVAL  = "AUDIT_FLUSH_INTERVAL_MS"
+	SCHEMA_BOOTSTRAP      = "SCHEMA_BOOTSTRAP"
+
+	// CONCURRENCY DEFAULTS
+	DEFAULT_ANALYZE_CONCURRENCY  = 16
+	DEFAULT_PER_HOST_CONCURRENCY = 4
+
+	// AUDIT SINKS
+	AUDIT_SINK_BIGQUERY = "bigquery"
+	AUDIT_SINK_FILE     = "file"
+	AUDIT_SINK_LOG      = "log"
+	AUDIT_SINK_MEMORY   = "memory"
+
+	// AUDIT WRITER
+	AUDIT_WRITER_BUFFERED        = "buffered"
+	AUDIT_WRITER_SYNC            = "sync"
+	DEFAULT_AUDIT_BUFFER_SIZE    = 10000
+	DEFAULT_AUDIT_BATCH_SIZE     = 500
+	DEFAULT_AUDIT_FLUSH_MS       = 1000
+	AUDIT_WRITE_TIMEOUT_SECONDS  = 30
+	AUDIT_SHUTDOWN_FLUSH_SECONDS = 10
+
+	// SCHEMA BOOTSTRAP
+	SCHEMA_MIGRATE           = "migrate"
+	SCHEMA_VERIFY            = "verify"
+	SCHEMA_OFF               = "off"
+	SCHEMA_BOOTSTRAP_SECONDS = 60
+
+	// EXECUTION TRACKING DEFAULTS
+	DEFAULT_EXECUTION_TIMEOUT_MINUTES = 60
 
 	// STATUS CONSTANTS
 	STARTED     = "STARTED"
@@ -27,23 +79,144 @@ const (
 	IN_PROGRESS = "IN_PROGRESS"
 	FAILED      = "FAILED"
 
+	// FILE STATUS CONSTANTS
+	FILE_STATUS_PENDING            = "pending"
+	FILE_STATUS_TRIGGERED          = "triggered"
+	FILE_STATUS_PLANNED            = "planned"
+	FILE_STATUS_QUEUED             = "queued"
+	FILE_STATUS_ALREADY_PROCESSED  = "skipped-already-processed"
+	FILE_STATUS_NO_MATCH           = "skipped-no-match"
+	FILE_STATUS_REJECTED_BY_POLICY = "rejected-by-policy"
+	FILE_STATUS_PROBE_FAILED       = "probe-failed"
+	FILE_STATUS_CHECK_FAILED       = "check-failed"
+	FILE_STATUS_TRIGGER_FAILED     = "trigger-failed"
+	FILE_STATUS_NOT_ATTEMPTED      = "not-attempted"
+
+	// REQUEST STATUS CONSTANTS
+	REQUEST_STATUS_ACCEPTED  = "accepted"
+	REQUEST_STATUS_RUNNING   = "running"
+	REQUEST_STATUS_COMPLETED = "completed"
+
+	// CONTRACT FILE QUEUE STATUS CONSTANTS
+	QUEUE_STATUS_QUEUED     = "QUEUED"
+	QUEUE_STATUS_DISPATCHED = "DISPATCHED"
+	QUEUE_STATUS_DONE       = "DONE"
+	QUEUE_STATUS_FAILED     = "FAILED"
+
+	// CONTRACT FILE QUEUE DISPATCH
+	DISPATCH_IMMEDIATE           = "immediate"
+	DISPATCH_DEFERRED            = "deferred"
+	DEFAULT_DISPATCH_MAX_RUNNING = 10
+	DEFAULT_DISPATCH_BATCH_SIZE  = 100
+	DISPATCH_RECONCILE_SECONDS   = 10
+	DML_ATTEMPTS                 = 5
+	DML_RETRY_BASE_MS            = 250
+
+	// EXECUTION STATE CONSTANTS
+	EXECUTION_SUCCEEDED = "succeeded"
+	EXECUTION_FAILED    = "failed"
+	EXECUTION_CANCELLED = "cancelled"
+	EXECUTION_TIMED_OUT = "timed-out"
+
+	// COMPUTE BACKENDS
+	BACKEND_CLOUD_RUN = "cloud-run"
+	BACKEND_BATCH     = "batch"
+
+	// BYTE-RANGE SHARDING
+	DEFAULT_SHARD_SIZE_MB = 1024
+	DEFAULT_MAX_SHARDS    = 16
+	SHARD_LIMIT           = 256 // keeps the manifest well below the env var size limit
+	SHARD_MANIFEST_ENV    = "SHARD_MANIFEST"
+	EXPECTED_SIZE_ENV     = "EXPECTED_UNCOMPRESSED_BYTES"
+	TASK_INDEX_ENV        = "CLOUD_RUN_TASK_INDEX"
+	TASK_COUNT_ENV        = "CLOUD_RUN_TASK_COUNT"
+
+	// JOB PAYLOAD
+	JOB_PAYLOAD_VERSION          = "1"
+	PAYLOAD_POSITIONAL           = "positional"
+	PAYLOAD_ARG                  = "arg"
+	PAYLOAD_ENV                  = "env"
+	JOB_PAYLOAD_ENV              = "JOB_PAYLOAD"
+	JOB_PAYLOAD_PATH_ENV         = "JOB_PAYLOAD_PATH"
+	JOB_PAYLOAD_MAX_INLINE_BYTES = 16 << 10
+	JOB_PAYLOAD_PREFIX           = "job-payloads"
+
+	// JOB LAUNCHERS
+	LAUNCHER_CLOUD  = "cloud"
+	LAUNCHER_LOCAL  = "local"
+	LAUNCHER_MEMORY = "memory"
+
+	// CLOUD BATCH DEFAULTS
+	BATCH_DEFAULT_MACHINE_TYPE = "e2-standard-4"
+	BATCH_MIN_DISK_GB          = 50
+	BATCH_THROUGHPUT_MBPS      = 50
+	BATCH_MIN_RUN_MINUTES      = 30
+	BATCH_MAX_RUN_MINUTES      = 24 * 60
+	BATCH_JOB_PARENT           = "projects/%s/locations/%s"
+
+	// STATE STORE
+	STATE_STORE_GCS       = "gcs"
+	STATE_STORE_MEMORY    = "memory"
+	STATE_RETENTION_HOURS = 24
+	STATE_OBJECT_PREFIX   = "compute-decider/requests"
+	STATE_UPDATE_ATTEMPTS = 5
+
 	// EVENT CONSTANTS
 	APPLICATION_STARTED_EVENT      = "compute_decider.application_started"
 	REQUEST_BODY_FAILED            = "compute_decider.request_body_failed"
 	INVALID_JSON_FORMAT            = "compute_decider.invalid_json_format"
 	FILE_URL_MISSING               = "compute_decider.file_url_missing"
+	INVALID_DRY_RUN_PARAM          = "compute_decider.invalid_dry_run_parameter"
+	INVALID_ASYNC_PARAM            = "compute_decider.invalid_async_parameter"
 	ERROR_FETCHING_FILE_SIZE       = "compute_decider.error_fetching_file_size"
 	ANALYZE_FILE_STARTED           = "compute_decider.analyze_file_started"
 	ANALYZE_FILE_COMPLETED         = "compute_decider.analyze_file_completed"
 	TRIGGER_CLOUD_RUN_JOB          = "compute_decider.trigger_cloud_run_job"
 	TRIGGER_CLOUD_BATCH_JOB        = "compute_decider.trigger_cloud_batch_job"
+	CLOUD_RUN_JOB_TRIGGERED        = "compute_decider.cloud_run_job_triggered"
+	JOB_PLANNED                    = "compute_decider.job_planned"
+	CLOUD_RUN_EXECUTION_SUCCEEDED  = "compute_decider.cloud_run_execution_succeeded"
+	CLOUD_RUN_EXECUTION_FAILED     = "compute_decider.cloud_run_execution_failed"
+	CLOUD_RUN_EXECUTION_CANCELLED  = "compute_decider.cloud_run_execution_cancelled"
+	CLOUD_RUN_EXECUTION_TIMED_OUT  = "compute_decider.cloud_run_execution_tracking_timed_out"
 	FAILED_TRIGGER_CLOUD_RUN_JOB   = "compute_decider.trigger_cloud_run_job_failed"
 	FAILED_TRIGGER_CLOUD_BATCH_JOB = "compute_decider.trigger_cloud_batch_job_failed"
+	CLOUD_BATCH_JOB_SUBMITTED      = "compute_decider.cloud_batch_job_submitted"
 	FAILED_TO_CHECK_IF_FILE_EXISTS = "compute_decider.failed_to_check_file_exists"
+	NO_ROUTING_RULE_MATCHED        = "compute_decider.no_routing_rule_matched"
+	FILE_SIZE_LIMIT_EXCEEDED       = "compute_decider.file_size_limit_exceeded"
+	FILE_NOT_ATTEMPTED             = "compute_decider.file_not_attempted"
+	ROUTING_RULES_INVALID          = "compute_decider.routing_rules_invalid"
 	ERROR_CREATING_GCS_CLIENT      = "compute_decider.error_creating_gcs_client"
+	ASYNC_REQUEST_ACCEPTED         = "compute_decider.async_request_accepted"
+	STATE_STORE_FAILED             = "compute_decider.state_store_failed"
+	CONTRACT_FILE_QUEUED           = "compute_decider.contract_file_queued"
+	CONTRACT_QUEUE_UPDATE_FAILED   = "compute_decider.contract_queue_update_failed"
+	CONTRACT_FILE_DISPATCHED       = "compute_decider.contract_file_dispatched"
+	CONTRACT_FILE_DISPATCH_FAILED  = "compute_decider.contract_file_dispatch_failed"
+	DISPATCH_FAILED                = "compute_decider.dispatch_failed"
+	SCHEMA_CHECK_FAILED            = "compute_decider.schema_check_failed"
 	APPLICATION_COMPLETED_EVENT    = "compute_decider.application_completed"
 
-	// MAX FILE SIZE
+	// SIZE PROBING
+	PROBE_STREAM_MAX_BYTES  = 64 << 20
+	SIZE_FROM_HEAD          = "head"
+	SIZE_FROM_RANGE_GET     = "range-get"
+	SIZE_FROM_STREAMING_GET = "streaming-get"
+	SIZE_TIER_UNKNOWN       = "unknown"
+
+	// FORMAT DETECTION
+	SNIFF_BYTES              = 512
+	DETECTED_BY_EXTENSION    = "extension"
+	DETECTED_BY_CONTENT_TYPE = "content-type"
+	DETECTED_BY_MAGIC_BYTES  = "magic-bytes"
+
+	// ARCHIVE INSPECTION
+	ZIP_MAX_CENTRAL_DIRECTORY_BYTES = 16 << 20
+	ZIP_MAX_ENTRY_NAMES             = 100
+	GZIP_HEADER_BYTES               = 64
+
+	// MAX FILE SIZE (GB), used when the size policy does not set one
 	MAX_FILE_SIZE = 25
 
 	// FILE EXTENSIONS
@@ -54,10 +227,12 @@ const (
 	ENVIRONMENT = "DEV"
 
 	// JOB NAME
-	CLOUD_RUN_JOB_NAME      = "prj-wayne-file-streamer"
-	GZ_JOB_NAME             = "prj-wayne-gz-streamer"
-	ZIP_DOWNLOADER_JOB_NAME = "prj-wayne-zip-downloader"
-	JOB_PREFIX              = "projects/%s/locations/%s/jobs/%s"
+	JOB_PREFIX = "projects/%s/locations/%s/jobs/%s"
 
-	HEALTH = "/health"
+	HEALTH        = "/health"
+	PLAN          = "/plan"
+	DISPATCH      = "/dispatch"
+	REQUESTS      = "/requests/"
+	DRY_RUN_PARAM = "dryRun"
+	ASYNC_PARAM   = "async"
 )